@@ -55,7 +55,15 @@ func (s *NotificationEndpointService) FindNotificationEndpoints(ctx context.Cont
 
 	// TODO: we'll likely want to push this operation into the database eventually since fetching the whole list of data
 	// will likely be expensive.
-	edps, _, err := s.s.FindNotificationEndpoints(ctx, filter, opt...)
+	// The endpoints the caller can't read are dropped before paging, so pages and the
+	// total only ever count readable endpoints.
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	all := o
+	all.Offset, all.Limit = 0, 0
+	edps, _, err := s.s.FindNotificationEndpoints(ctx, filter, all)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -76,7 +84,7 @@ func (s *NotificationEndpointService) FindNotificationEndpoints(ctx context.Cont
 		endpoints = append(endpoints, edp)
 	}
 
-	return endpoints, len(endpoints), nil
+	return influxdb.PageNotificationEndpoints(o, endpoints), len(endpoints), nil
 }
 
 // CreateNotificationEndpoint checks to see if the authorizer on context has write access to the global notification endpoint resource.
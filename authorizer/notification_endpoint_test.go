@@ -210,6 +210,47 @@ func TestNotificationEndpointService_FindNotificationEndpoints(t *testing.T) {
 	}
 }
 
+func TestNotificationEndpointService_FindNotificationEndpoints_Paging(t *testing.T) {
+	var gotOpts influxdb.FindOptions
+	svc := &mock.NotificationEndpointService{
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			gotOpts = opt[0]
+			return []influxdb.NotificationEndpoint{
+				&endpoint.Slack{Base: endpoint.Base{ID: idPtr(1), OrgID: idPtr(11)}},
+				&endpoint.Slack{Base: endpoint.Base{ID: idPtr(2), OrgID: idPtr(10)}},
+				&endpoint.Slack{Base: endpoint.Base{ID: idPtr(3), OrgID: idPtr(11)}},
+				&endpoint.Slack{Base: endpoint.Base{ID: idPtr(4), OrgID: idPtr(10)}},
+			}, 4, nil
+		},
+	}
+	s := authorizer.NewNotificationEndpointService(svc,
+		mock.NewUserResourceMappingService(),
+		mock.NewOrganizationService())
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{{
+		Action:   "read",
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: influxdbtesting.IDPtr(10)},
+	}}})
+
+	oid := influxdb.ID(10)
+	edps, n, err := s.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &oid},
+		influxdb.FindOptions{Offset: 1, Limit: 1, SortBy: "name"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the store is asked for every match so the unreadable ones are dropped before paging.
+	if want := (influxdb.FindOptions{SortBy: "name"}); gotOpts != want {
+		t.Errorf("expected the store to be asked for %+v, got %+v", want, gotOpts)
+	}
+	if n != 2 {
+		t.Errorf("expected a total of 2 readable notification endpoints, got %d", n)
+	}
+	if len(edps) != 1 || edps[0].GetID() != 4 {
+		t.Errorf("expected the second readable notification endpoint, got %v", edps)
+	}
+}
+
 func TestNotificationEndpointService_UpdateNotificationEndpoint(t *testing.T) {
 	type fields struct {
 		NotificationEndpointService influxdb.NotificationEndpointService
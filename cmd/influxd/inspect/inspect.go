@@ -24,6 +24,9 @@ func NewCommand() *cobra.Command {
 		NewVerifySeriesFileCommand(),
 		NewDumpWALCommand(),
 		NewDumpTSICommand(),
+		NewReportEndpointsCommand(),
+		NewRepairEndpointSecretsCommand(),
+		NewVerifyEndpointSecretsCommand(),
 	}
 
 	base.AddCommand(subCommands...)
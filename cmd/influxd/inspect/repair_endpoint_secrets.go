@@ -0,0 +1,96 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	bbolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/endpoints"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewRepairEndpointSecretsCommand returns the `repair-endpoint-secrets` command.
+func NewRepairEndpointSecretsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repair-endpoint-secrets",
+		Short: "Moves secret values stored on notification endpoints into the secret store",
+		Long: `
+This command finds notification endpoints holding secret values that were never
+moved into the secret store, e.g. after a migration, and moves them there so no
+secret is left in plaintext on the endpoint. The server must be stopped.
+
+When no organization is provided, the endpoints of every organization are repaired.`,
+		Args: cobra.NoArgs,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+
+	var (
+		boltPath string
+		orgID    string
+	)
+	cmd.Flags().StringVar(&boltPath, "bolt-path", filepath.Join(dir, "influxd.bolt"), "path to boltdb database")
+	cmd.Flags().StringVar(&orgID, "org-id", "", "ID of the organization to repair")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		var orgIDs []influxdb.ID
+		if orgID != "" {
+			id, err := influxdb.IDFromString(orgID)
+			if err != nil {
+				return fmt.Errorf("invalid org id %q: %v", orgID, err)
+			}
+			orgIDs = append(orgIDs, *id)
+		}
+		return repairEndpointSecrets(context.Background(), cmd.OutOrStdout(), boltPath, orgIDs)
+	}
+
+	return cmd
+}
+
+func repairEndpointSecrets(ctx context.Context, w io.Writer, boltPath string, orgIDs []influxdb.ID) error {
+	db, err := bbolt.Open(boltPath, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("unable to open boltdb file %v", err)
+	}
+	defer db.Close()
+
+	store := bolt.NewKVStore(zap.NewNop(), boltPath)
+	store.WithDB(db)
+	svc := kv.NewService(zap.NewNop(), store)
+	edpSVC := endpoints.NewService(svc, svc, svc, svc)
+
+	if len(orgIDs) == 0 {
+		orgs, _, err := svc.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+		if err != nil {
+			return err
+		}
+		for _, o := range orgs {
+			orgIDs = append(orgIDs, o.ID)
+		}
+	}
+
+	var n int
+	for _, orgID := range orgIDs {
+		repaired, err := edpSVC.RepairSecrets(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		for _, edp := range repaired {
+			fmt.Fprintf(w, "repaired notification endpoint %s (%s) of org %s\n", edp.GetID(), edp.GetName(), orgID)
+		}
+		n += len(repaired)
+	}
+	fmt.Fprintf(w, "%d notification endpoints repaired\n", n)
+	return nil
+}
@@ -0,0 +1,87 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	bbolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewReportEndpointsCommand returns the `notification-endpoints` command.
+func NewReportEndpointsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notification-endpoints",
+		Short: "Lists all notification endpoints in a bolt database",
+		Long: `
+This command opens the bolt database read-only and lists every notification
+endpoint it contains, without the server running. For each endpoint, the ID,
+organization ID, type, name and status are output.
+
+Secret fields are only listed by their key, their values are never read.`,
+		Args: cobra.NoArgs,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+
+	var boltPath string
+	cmd.Flags().StringVar(&boltPath, "bolt-path", filepath.Join(dir, "influxd.bolt"), "path to boltdb database")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return reportEndpoints(context.Background(), cmd.OutOrStdout(), boltPath)
+	}
+
+	return cmd
+}
+
+func reportEndpoints(ctx context.Context, w io.Writer, boltPath string) error {
+	db, err := bbolt.Open(boltPath, 0600, &bbolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("unable to open boltdb file %v", err)
+	}
+	defer db.Close()
+
+	store := bolt.NewKVStore(zap.NewNop(), boltPath)
+	store.WithDB(db)
+	svc := kv.NewService(zap.NewNop(), store)
+
+	edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "ID\tOrgID\tType\tName\tStatus\tSecrets")
+	for _, edp := range edps {
+		var keys []string
+		for _, fld := range edp.SecretFields() {
+			keys = append(keys, fld.Key)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			edp.GetID(),
+			edp.GetOrgID(),
+			edp.Type(),
+			edp.GetName(),
+			edp.GetStatus(),
+			strings.Join(keys, ","),
+		)
+	}
+	return tw.Flush()
+}
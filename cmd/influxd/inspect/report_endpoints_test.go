@@ -0,0 +1,92 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestReportEndpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxd-inspect-endpoints-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "influxd.bolt")
+
+	edp := seedEndpoints(t, path)
+
+	cmd := NewReportEndpointsCommand()
+	var buf bytes.Buffer
+	cmd.SetOutput(&buf)
+	cmd.SetArgs([]string{"--bolt-path", path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 endpoint, got:\n%s", buf.String())
+	}
+	for _, want := range []string{
+		edp.GetID().String(),
+		edp.GetOrgID().String(),
+		endpoint.PagerDutyType,
+		"pager1",
+		string(influxdb.Active),
+		edp.GetID().String() + "-routing-key",
+	} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("expected %q in output, got %q", want, lines[1])
+		}
+	}
+	if strings.Contains(buf.String(), "s3cr3t") {
+		t.Errorf("secret value must not be reported, got:\n%s", buf.String())
+	}
+}
+
+func seedEndpoints(t *testing.T, path string) influxdb.NotificationEndpoint {
+	t.Helper()
+	ctx := context.Background()
+
+	store := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	if err := store.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org1"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := "s3cr3t"
+	edp := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "pager1",
+			Status: influxdb.Active,
+		},
+		ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey: influxdb.SecretField{Value: &secret},
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+	return edp
+}
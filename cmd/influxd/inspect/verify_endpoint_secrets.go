@@ -0,0 +1,114 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	bbolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewVerifyEndpointSecretsCommand returns the `verify-endpoint-secrets` command.
+func NewVerifyEndpointSecretsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-endpoint-secrets",
+		Short: "Verifies that the secrets of all notification endpoints resolve",
+		Long: `
+This command opens the bolt database read-only and checks that every secret key
+referenced by a notification endpoint is present in the secret store of the
+endpoint's organization. Dangling references, e.g. lost during a migration, are
+reported and the command exits with an error. Secrets kept in a named secret
+store live outside the database, they are reported as unverifiable.
+
+Only secret keys are read, secret values are never output.`,
+		Args: cobra.NoArgs,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+
+	var boltPath string
+	cmd.Flags().StringVar(&boltPath, "bolt-path", filepath.Join(dir, "influxd.bolt"), "path to boltdb database")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return verifyEndpointSecrets(context.Background(), cmd.OutOrStdout(), boltPath)
+	}
+
+	return cmd
+}
+
+func verifyEndpointSecrets(ctx context.Context, w io.Writer, boltPath string) error {
+	db, err := bbolt.Open(boltPath, 0600, &bbolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("unable to open boltdb file %v", err)
+	}
+	defer db.Close()
+
+	store := bolt.NewKVStore(zap.NewNop(), boltPath)
+	store.WithDB(db)
+	svc := kv.NewService(zap.NewNop(), store)
+
+	edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// secret keys are loaded once per organization.
+	orgKeys := make(map[influxdb.ID]map[string]bool)
+	var dangling, unverifiable int
+	for _, edp := range edps {
+		orgID := edp.GetOrgID()
+		keys, ok := orgKeys[orgID]
+		if !ok {
+			ks, err := svc.GetSecretKeys(ctx, orgID)
+			if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+				return err
+			}
+			keys = make(map[string]bool, len(ks))
+			for _, k := range ks {
+				keys[k] = true
+			}
+			orgKeys[orgID] = keys
+		}
+
+		for _, fld := range edp.SecretFields() {
+			if fld.Key == "" {
+				continue
+			}
+			if fld.Store != "" {
+				fmt.Fprintf(w, "notification endpoint %s (%s) of org %s keeps secret %q in store %q, it can't be verified\n", edp.GetID(), edp.GetName(), orgID, fld.Key, fld.Store)
+				unverifiable++
+				continue
+			}
+			if keys[fld.Key] {
+				continue
+			}
+			fmt.Fprintf(w, "notification endpoint %s (%s) of org %s references missing secret %q\n", edp.GetID(), edp.GetName(), orgID, fld.Key)
+			dangling++
+		}
+	}
+
+	if dangling > 0 {
+		return fmt.Errorf("found %d dangling secret references", dangling)
+	}
+	if unverifiable > 0 {
+		fmt.Fprintf(w, "all secrets of %d notification endpoints resolve, %d in named stores were not verified\n", len(edps), unverifiable)
+		return nil
+	}
+	fmt.Fprintf(w, "all secrets of %d notification endpoints resolve\n", len(edps))
+	return nil
+}
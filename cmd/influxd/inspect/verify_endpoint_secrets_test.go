@@ -0,0 +1,113 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestVerifyEndpointSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxd-inspect-endpoint-secrets-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "influxd.bolt")
+
+	// the kv store does not write secrets, so the seeded routing key dangles.
+	missing := seedEndpoints(t, path)
+	resolved := seedEndpointWithSecret(t, path, missing.GetOrgID())
+	inStore := seedEndpointInSecretStore(t, path, missing.GetOrgID(), "kms")
+
+	cmd := NewVerifyEndpointSecretsCommand()
+	var buf bytes.Buffer
+	cmd.SetOutput(&buf)
+	cmd.SetArgs([]string{"--bolt-path", path})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected dangling secret references to fail the command, got:\n%s", buf.String())
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, missing.GetID().String()+"-routing-key") {
+		t.Errorf("expected the missing routing key to be reported, got:\n%s", out)
+	}
+	if strings.Contains(out, resolved.GetID().String()) {
+		t.Errorf("expected the resolved endpoint not to be reported, got:\n%s", out)
+	}
+	storeKey := inStore.GetID().String() + "-routing-key"
+	if strings.Contains(out, "references missing secret \""+storeKey+"\"") {
+		t.Errorf("expected the secret in a named store not to be reported as missing, got:\n%s", out)
+	}
+	if !strings.Contains(out, "keeps secret \""+storeKey+"\" in store \"kms\", it can't be verified") {
+		t.Errorf("expected the secret in a named store to be reported as unverifiable, got:\n%s", out)
+	}
+}
+
+// seedEndpointInSecretStore creates an endpoint whose routing key is kept in the named
+// secret store, the default store has no secret for it.
+func seedEndpointInSecretStore(t *testing.T, path string, orgID influxdb.ID, storeName string) influxdb.NotificationEndpoint {
+	t.Helper()
+	ctx := context.Background()
+
+	store := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	if err := store.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	secret := "s3cr3t"
+	edp := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			OrgID:  &orgID,
+			Name:   "pager3",
+			Status: influxdb.Active,
+		},
+		ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey: influxdb.SecretField{Value: &secret, Store: storeName},
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+	return edp
+}
+
+func seedEndpointWithSecret(t *testing.T, path string, orgID influxdb.ID) influxdb.NotificationEndpoint {
+	t.Helper()
+	ctx := context.Background()
+
+	store := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	if err := store.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	secret := "s3cr3t"
+	edp := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			OrgID:  &orgID,
+			Name:   "pager2",
+			Status: influxdb.Active,
+		},
+		ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey: influxdb.SecretField{Value: &secret},
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutSecret(ctx, orgID, edp.RoutingKey.Key, secret); err != nil {
+		t.Fatal(err)
+	}
+	return edp
+}
@@ -763,6 +763,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		Addr: m.httpBindAddress,
 	}
 
+	notificationEndpointSvc := endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc)
+
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           m.assetsPath,
 		HTTPErrorHandler:     http.ErrorHandler(0),
@@ -794,7 +796,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		TaskService:                     taskSvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
-		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
+		NotificationEndpointService:     notificationEndpointSvc,
 		CheckService:                    checkSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
 		ChronografService:               chronografSvc,
@@ -804,6 +806,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		OrgLookupService:                m.kvService,
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
+		ResourceOwnershipService:        m.kvService,
+
+		NotificationEndpointDefaultsService:   m.kvService,
+		NotificationEndpointGenerationService: m.kvService,
+		NotificationEndpointHealthService:     notificationEndpointSvc,
+		NotificationEndpointHistoryService:    m.kvService,
+		NotificationEndpointSecretService:     notificationEndpointSvc,
 	}
 
 	m.reg.MustRegister(m.apibackend.PrometheusCollectors()...)
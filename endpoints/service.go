@@ -2,31 +2,72 @@ package endpoints
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/endpoint"
 )
 
 // Service provides all the notification endpoint service behavior.
 type Service struct {
 	endpointStore influxdb.NotificationEndpointService
 	secretSVC     influxdb.SecretService
+	// secretStores are the named secret stores secret fields may ask for.
+	secretStores map[string]influxdb.SecretService
+	// check is the connectivity check run by CheckAll.
+	check ConnectivityCheck
 
 	// TODO(jsteenb2): NUKE THESE 2 embedded services after fixing up the domain!
 	influxdb.UserResourceMappingService
 	influxdb.OrganizationService
 }
 
+// ServiceOptFn is a functional option for configuring the Service.
+type ServiceOptFn func(*Service)
+
+// WithSecretStore registers a named secret store. The values of secret fields whose
+// store hint is name are kept there instead of the default secret service.
+func WithSecretStore(name string, secretSVC influxdb.SecretService) ServiceOptFn {
+	return func(s *Service) {
+		s.secretStores[name] = secretSVC
+	}
+}
+
+// ConnectivityCheck checks that a notification endpoint can be reached, returning nil when it can.
+type ConnectivityCheck func(ctx context.Context, edp influxdb.NotificationEndpoint) error
+
+// WithConnectivityCheck replaces the connectivity check run by CheckAll, DialNotificationEndpoint
+// by default.
+func WithConnectivityCheck(check ConnectivityCheck) ServiceOptFn {
+	return func(s *Service) {
+		s.check = check
+	}
+}
+
 // NewService constructs a new Service.
-func NewService(store influxdb.NotificationEndpointService, secretSVC influxdb.SecretService, urmSVC influxdb.UserResourceMappingService, orgSVC influxdb.OrganizationService) *Service {
-	return &Service{
+func NewService(store influxdb.NotificationEndpointService, secretSVC influxdb.SecretService, urmSVC influxdb.UserResourceMappingService, orgSVC influxdb.OrganizationService, opts ...ServiceOptFn) *Service {
+	s := &Service{
 		endpointStore:              store,
 		secretSVC:                  secretSVC,
+		secretStores:               make(map[string]influxdb.SecretService),
+		check:                      DialNotificationEndpoint,
 		UserResourceMappingService: urmSVC,
 		OrganizationService:        orgSVC,
 	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
-var _ influxdb.NotificationEndpointService = (*Service)(nil)
+var (
+	_ influxdb.NotificationEndpointService       = (*Service)(nil)
+	_ influxdb.NotificationEndpointHealthService = (*Service)(nil)
+	_ influxdb.NotificationEndpointSecretService = (*Service)(nil)
+)
 
 // FindNotificationEndpointByID returns a single notification endpoint by ID.
 func (s *Service) FindNotificationEndpointByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
@@ -41,45 +82,39 @@ func (s *Service) FindNotificationEndpoints(ctx context.Context, filter influxdb
 
 // CreateNotificationEndpoint creates a new notification endpoint and sets b.ID with the new identifier.
 func (s *Service) CreateNotificationEndpoint(ctx context.Context, edp influxdb.NotificationEndpoint, userID influxdb.ID) error {
+	edp.Normalize()
+	// the store hints are set on the incoming fields, so they are checked before
+	// anything is persisted even though the keys are only backfilled by the store.
+	if err := s.validSecretStores(endpoint.SetSecretFields(edp)); err != nil {
+		return err
+	}
+	// the endpoint has no ID yet, so it is validated by the store once it is assigned
+	// one, before anything is persisted.
 	err := s.endpointStore.CreateNotificationEndpoint(ctx, edp, userID)
 	if err != nil {
 		return err
 	}
 
-	secrets := make(map[string]string)
-	for _, fld := range edp.SecretFields() {
-		if fld.Value != nil {
-			secrets[fld.Key] = *fld.Value
-		}
-	}
-	if len(secrets) == 0 {
-		return nil
-	}
-
-	return s.secretSVC.PutSecrets(ctx, edp.GetOrgID(), secrets)
+	return s.patchSecrets(ctx, edp.GetOrgID(), edp.SecretFields())
 }
 
 // UpdateNotificationEndpoint updates a single notification endpoint.
 // Returns the new notification endpoint after update.
 func (s *Service) UpdateNotificationEndpoint(ctx context.Context, id influxdb.ID, nr influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	nr.Normalize()
 	nr.BackfillSecretKeys() // :sadpanda:
-	updatedEndpoint, err := s.endpointStore.UpdateNotificationEndpoint(ctx, id, nr, userID)
-	if err != nil {
+	if err := nr.Valid(); err != nil {
 		return nil, err
 	}
-
-	secrets := make(map[string]string)
-	for _, fld := range updatedEndpoint.SecretFields() {
-		if fld.Value != nil {
-			secrets[fld.Key] = *fld.Value
-		}
+	if err := s.validSecretStores(nr.SecretFields()); err != nil {
+		return nil, err
 	}
-
-	if len(secrets) == 0 {
-		return updatedEndpoint, nil
+	updatedEndpoint, err := s.endpointStore.UpdateNotificationEndpoint(ctx, id, nr, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.secretSVC.PutSecrets(ctx, updatedEndpoint.GetOrgID(), secrets); err != nil {
+	if err := s.patchSecrets(ctx, updatedEndpoint.GetOrgID(), updatedEndpoint.SecretFields()); err != nil {
 		return nil, err
 	}
 
@@ -92,7 +127,207 @@ func (s *Service) PatchNotificationEndpoint(ctx context.Context, id influxdb.ID,
 	return s.endpointStore.PatchNotificationEndpoint(ctx, id, upd)
 }
 
-// DeleteNotificationEndpoint removes a notification endpoint by ID, returns secret fields, orgID for further deletion.
+// DeleteNotificationEndpoint removes a notification endpoint by ID along with its secrets,
+// unless the context carries the keep secrets hint. Returns secret fields, orgID of the endpoint.
 func (s *Service) DeleteNotificationEndpoint(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
-	return s.endpointStore.DeleteNotificationEndpoint(ctx, id)
+	flds, orgID, err := s.endpointStore.DeleteNotificationEndpoint(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if influxdb.NotificationEndpointSecretsKept(ctx) {
+		return flds, orgID, nil
+	}
+
+	keysByStore := make(map[string][]string)
+	for _, fld := range flds {
+		if fld.Key != "" {
+			keysByStore[fld.Store] = append(keysByStore[fld.Store], fld.Key)
+		}
+	}
+	for name, keys := range keysByStore {
+		secretSVC, err := s.secretStore(name)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := secretSVC.DeleteSecret(ctx, orgID, keys...); err != nil {
+			return nil, 0, err
+		}
+	}
+	return flds, orgID, nil
+}
+
+// RepairSecrets finds the org's notification endpoints holding secret values that were never
+// moved into the secret service, backfills their secret keys, stores the values in the secret
+// service and rewrites the endpoints without them. Returns the repaired endpoints.
+func (s *Service) RepairSecrets(ctx context.Context, orgID influxdb.ID) ([]influxdb.NotificationEndpoint, error) {
+	edps, _, err := s.endpointStore.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &orgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var repaired []influxdb.NotificationEndpoint
+	for _, edp := range edps {
+		backfilled := make(map[string]bool)
+		for _, fld := range edp.SecretFields() {
+			backfilled[fld.Key] = true
+		}
+		// only the fields without a key are backfilled, those are the half-baked ones.
+		edp.BackfillSecretKeys()
+
+		var flds []influxdb.SecretField
+		for _, fld := range edp.SecretFields() {
+			if !backfilled[fld.Key] && fld.Value != nil {
+				flds = append(flds, fld)
+			}
+		}
+		if len(flds) == 0 {
+			continue
+		}
+
+		// store the secrets first, so the rewritten endpoint never references missing secrets.
+		if err := s.validSecretStores(flds); err != nil {
+			return nil, err
+		}
+		if err := s.patchSecrets(ctx, edp.GetOrgID(), flds); err != nil {
+			return nil, err
+		}
+		updated, err := s.endpointStore.UpdateNotificationEndpoint(ctx, edp.GetID(), edp, 0)
+		if err != nil {
+			return nil, err
+		}
+		repaired = append(repaired, updated)
+	}
+	return repaired, nil
+}
+
+// CheckAll runs the connectivity check of every active notification endpoint of the org, one
+// after the other, and returns their health in the order the endpoints are listed. A failed
+// check marks its endpoint unhealthy rather than failing the whole run.
+func (s *Service) CheckAll(ctx context.Context, orgID influxdb.ID) ([]influxdb.NotificationEndpointHealth, error) {
+	edps, _, err := s.endpointStore.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &orgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]influxdb.NotificationEndpointHealth, 0, len(edps))
+	for _, edp := range edps {
+		if edp.GetStatus() != influxdb.Active {
+			continue
+		}
+		h := influxdb.NotificationEndpointHealth{
+			ID:      edp.GetID(),
+			Name:    edp.GetName(),
+			Healthy: true,
+		}
+		if err := s.check(ctx, edp); err != nil {
+			h.Healthy = false
+			h.Error = err.Error()
+		}
+		health = append(health, h)
+	}
+	return health, nil
+}
+
+// connectivityCheckTimeout bounds the time DialNotificationEndpoint waits for a connection.
+const connectivityCheckTimeout = 5 * time.Second
+
+// DialNotificationEndpoint is the default connectivity check. It opens, and closes again, a tcp
+// connection to the host the notification endpoint sends alerts to, nothing is sent.
+func DialNotificationEndpoint(ctx context.Context, edp influxdb.NotificationEndpoint) error {
+	u, err := url.Parse(endpoint.DeliveryURL(edp))
+	if err != nil {
+		return err
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("notification endpoint %q has no host to send alerts to", edp.GetName())
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, connectivityCheckTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// secretStore returns the secret service for the store hint of a secret field.
+func (s *Service) secretStore(name string) (influxdb.SecretService, error) {
+	if name == "" {
+		return s.secretSVC, nil
+	}
+	secretSVC, ok := s.secretStores[name]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown secret store %q", name),
+		}
+	}
+	return secretSVC, nil
+}
+
+// ValidSecretStores returns an error if a secret field asks for an unknown secret store.
+func (s *Service) ValidSecretStores(flds []influxdb.SecretField) error {
+	return s.validSecretStores(flds)
+}
+
+func (s *Service) validSecretStores(flds []influxdb.SecretField) error {
+	for _, fld := range flds {
+		if _, err := s.secretStore(fld.Store); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PatchNotificationEndpointSecrets stores the values of the secret fields of the org,
+// each in the store it asks for, e.g. when a secret is rotated.
+func (s *Service) PatchNotificationEndpointSecrets(ctx context.Context, orgID influxdb.ID, flds []influxdb.SecretField) error {
+	if err := s.validSecretStores(flds); err != nil {
+		return err
+	}
+	return s.patchSecrets(ctx, orgID, flds)
+}
+
+// patchSecrets stores the values of the secret fields, each in the store it asks for.
+func (s *Service) patchSecrets(ctx context.Context, orgID influxdb.ID, flds []influxdb.SecretField) error {
+	secretsByStore := make(map[string]map[string]string)
+	for _, fld := range flds {
+		if fld.Value == nil {
+			continue
+		}
+		if secretsByStore[fld.Store] == nil {
+			secretsByStore[fld.Store] = make(map[string]string)
+		}
+		secretsByStore[fld.Store][fld.Key] = *fld.Value
+	}
+
+	for name, secrets := range secretsByStore {
+		secretSVC, err := s.secretStore(name)
+		if err != nil {
+			return err
+		}
+		if err := secretSVC.PatchSecrets(ctx, orgID, secrets); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,283 @@
+package endpoints_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/endpoints"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_RepairSecrets(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	require.NoError(t, svc.Initialize(ctx))
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	edpSVC := endpoints.NewService(svc, svc, svc, svc)
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, edpSVC.CreateNotificationEndpoint(ctx, edp, 1))
+
+	// seed a half-baked endpoint, whose token value was never moved into the secret store.
+	key, err := edp.GetID().Encode()
+	require.NoError(t, err)
+	raw := `{"id":"` + edp.GetID().String() + `","orgID":"` + org.ID.String() + `","name":"slack","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z","token":"plaintext"}`
+	require.NoError(t, store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("notificationEndpointv1"))
+		if err != nil {
+			return err
+		}
+		return b.Put(key, []byte(raw))
+	}))
+
+	repaired, err := edpSVC.RepairSecrets(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, repaired, 1)
+	assert.Equal(t, edp.GetID(), repaired[0].GetID())
+
+	tokenKey := edp.GetID().String() + "-token"
+	secret, err := svc.LoadSecret(ctx, org.ID, tokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", secret)
+
+	require.NoError(t, store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("notificationEndpointv1"))
+		if err != nil {
+			return err
+		}
+		v, err := b.Get(key)
+		if err != nil {
+			return err
+		}
+		assert.NotContains(t, string(v), "plaintext")
+		assert.Contains(t, string(v), "secret: "+tokenKey)
+		return nil
+	}))
+
+	repaired, err = edpSVC.RepairSecrets(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, repaired)
+}
+
+func TestService_SecretStoreHints(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	require.NoError(t, svc.Initialize(ctx))
+	kms := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	require.NoError(t, kms.Initialize(ctx))
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	edpSVC := endpoints.NewService(svc, svc, svc, svc, endpoints.WithSecretStore("kms", kms))
+	username, password := "user", "s3cr3t"
+	edp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "http",
+			Status: influxdb.Active,
+		},
+		URL:        "https://example.com/hook",
+		Method:     "POST",
+		AuthMethod: "basic",
+		Username:   influxdb.SecretField{Value: &username},
+		Password:   influxdb.SecretField{Value: &password, Store: "kms"},
+	}
+	require.NoError(t, edpSVC.CreateNotificationEndpoint(ctx, edp, 1))
+
+	usernameKey := edp.GetID().String() + "-username"
+	passwordKey := edp.GetID().String() + "-password"
+
+	v, err := svc.LoadSecret(ctx, org.ID, usernameKey)
+	require.NoError(t, err)
+	assert.Equal(t, username, v)
+	_, err = svc.LoadSecret(ctx, org.ID, passwordKey)
+	assert.Error(t, err, "the password must not be kept in the default store")
+
+	v, err = kms.LoadSecret(ctx, org.ID, passwordKey)
+	require.NoError(t, err)
+	assert.Equal(t, password, v)
+
+	found, err := edpSVC.FindNotificationEndpointByID(ctx, edp.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "kms", found.(*endpoint.HTTP).Password.Store)
+
+	t.Run("unknown store is rejected", func(t *testing.T) {
+		token := "t0k3n"
+		edp := &endpoint.HTTP{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   "vault",
+				Status: influxdb.Active,
+			},
+			URL:        "https://example.com/hook",
+			Method:     "POST",
+			AuthMethod: "bearer",
+			Token:      influxdb.SecretField{Value: &token, Store: "vault"},
+		}
+		err := edpSVC.CreateNotificationEndpoint(ctx, edp, 1)
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+		// the hint is checked before the endpoint is persisted, it is never assigned an ID.
+		assert.False(t, edp.GetID().Valid())
+		edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &org.ID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, edps, 1)
+		assert.Equal(t, "http", edps[0].GetName())
+	})
+}
+
+func TestService_ValidatesEndpoints(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	require.NoError(t, svc.Initialize(ctx))
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	edpSVC := endpoints.NewService(svc, svc, svc, svc)
+	filter := influxdb.NotificationEndpointFilter{
+		OrgID: &org.ID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	}
+
+	t.Run("create", func(t *testing.T) {
+		err := edpSVC.CreateNotificationEndpoint(ctx, &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   "slack",
+				Status: influxdb.Active,
+			},
+		}, 1)
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+		edps, _, err := svc.FindNotificationEndpoints(ctx, filter)
+		require.NoError(t, err)
+		assert.Empty(t, edps)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   "valid",
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x/y/z",
+		}
+		require.NoError(t, edpSVC.CreateNotificationEndpoint(ctx, edp, 1))
+
+		secret := "s3cr3t"
+		_, err := edpSVC.UpdateNotificationEndpoint(ctx, edp.GetID(), &endpoint.Slack{
+			Base: endpoint.Base{
+				ID:     edp.ID,
+				OrgID:  &org.ID,
+				Name:   "valid",
+				Status: influxdb.Active,
+			},
+			Token: influxdb.SecretField{Value: &secret},
+		}, 1)
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+		got, err := svc.FindNotificationEndpointByID(ctx, edp.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.slack.com/services/x/y/z", got.(*endpoint.Slack).URL)
+
+		_, err = svc.LoadSecret(ctx, org.ID, edp.GetID().String()+"-token")
+		assert.Error(t, err)
+	})
+}
+
+func TestService_CheckAll(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	require.NoError(t, svc.Initialize(ctx))
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+
+	// reserve a port and free it again, so that nothing is listening on it.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unhealthyURL := "http://" + l.Addr().String()
+	require.NoError(t, l.Close())
+
+	newHTTP := func(name, url string, status influxdb.Status) *endpoint.HTTP {
+		return &endpoint.HTTP{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   name,
+				Status: status,
+			},
+			URL:        url,
+			Method:     "POST",
+			AuthMethod: "none",
+		}
+	}
+
+	edpSVC := endpoints.NewService(svc, svc, svc, svc)
+	up := newHTTP("up", healthy.URL, influxdb.Active)
+	down := newHTTP("down", unhealthyURL, influxdb.Active)
+	require.NoError(t, edpSVC.CreateNotificationEndpoint(ctx, up, 1))
+	require.NoError(t, edpSVC.CreateNotificationEndpoint(ctx, down, 1))
+	require.NoError(t, edpSVC.CreateNotificationEndpoint(ctx, newHTTP("inactive", unhealthyURL, influxdb.Inactive), 1))
+
+	health, err := edpSVC.CheckAll(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, health, 2)
+
+	byID := make(map[influxdb.ID]influxdb.NotificationEndpointHealth)
+	for _, h := range health {
+		byID[h.ID] = h
+	}
+	assert.True(t, byID[up.GetID()].Healthy)
+	assert.Empty(t, byID[up.GetID()].Error)
+	assert.False(t, byID[down.GetID()].Healthy)
+	assert.NotEmpty(t, byID[down.GetID()].Error)
+
+	t.Run("custom check", func(t *testing.T) {
+		edpSVC := endpoints.NewService(svc, svc, svc, svc, endpoints.WithConnectivityCheck(
+			func(ctx context.Context, edp influxdb.NotificationEndpoint) error {
+				return nil
+			},
+		))
+		health, err := edpSVC.CheckAll(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, health, 2)
+		for _, h := range health {
+			assert.True(t, h.Healthy, h.Name)
+		}
+	})
+}
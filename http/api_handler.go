@@ -65,6 +65,13 @@ type APIBackend struct {
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	ResourceOwnershipService        influxdb.ResourceOwnershipService
+
+	NotificationEndpointDefaultsService   influxdb.NotificationEndpointDefaultsService
+	NotificationEndpointGenerationService influxdb.NotificationEndpointGenerationService
+	NotificationEndpointHealthService     influxdb.NotificationEndpointHealthService
+	NotificationEndpointHistoryService    influxdb.NotificationEndpointHistoryService
+	NotificationEndpointSecretService     influxdb.NotificationEndpointSecretService
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -145,6 +152,9 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
 	notificationEndpointBackend.NotificationEndpointService = authorizer.NewNotificationEndpointService(b.NotificationEndpointService,
 		b.UserResourceMappingService, b.OrganizationService)
+	notificationEndpointBackend.SecretService = authorizer.NewSecretService(b.SecretService)
+	notificationEndpointBackend.NotificationRuleStore = authorizer.NewNotificationRuleStore(b.NotificationRuleStore,
+		b.UserResourceMappingService, b.OrganizationService)
 	h.Mount(prefixNotificationEndpoints, NewNotificationEndpointHandler(notificationEndpointBackend.Logger(), notificationEndpointBackend))
 
 	notificationRuleBackend := NewNotificationRuleBackend(b.Logger.With(zap.String("handler", "notification_rule")), b)
@@ -9,10 +9,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/middleware"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"go.uber.org/zap"
 )
 
+// RequestIDHeader is the header used to propagate the request ID.
+const RequestIDHeader = "X-Request-Id"
+
 // Middleware constructor.
 type Middleware func(http.Handler) http.Handler
 
@@ -25,6 +29,16 @@ func traceMW(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// requestIDMW reads the request ID from the X-Request-Id header, generating one if it
+// is missing, stores it in the request context and echoes it in the response header.
+func requestIDMW(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	}
+	return middleware.RequestID(http.HandlerFunc(fn))
+}
+
 func skipOptionsMW(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
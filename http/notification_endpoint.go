@@ -1,15 +1,24 @@
 package http
 
 import (
-	"bytes"
+	"archive/tar"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/middleware"
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	pctx "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/notification/endpoint"
 	"github.com/influxdata/influxdb/pkg/httpc"
@@ -27,11 +36,59 @@ type NotificationEndpointBackend struct {
 	LabelService                influxdb.LabelService
 	UserService                 influxdb.UserService
 	OrganizationService         influxdb.OrganizationService
+	SecretService               influxdb.SecretService
+	ResourceOwnershipService    influxdb.ResourceOwnershipService
+	GenerationService           influxdb.NotificationEndpointGenerationService
+	HealthService               influxdb.NotificationEndpointHealthService
+	HistoryService              influxdb.NotificationEndpointHistoryService
+	NotificationRuleStore       influxdb.NotificationRuleStore
+	EndpointSecretService       influxdb.NotificationEndpointSecretService
+
+	clientProvidedIDs     bool
+	hideSecretReferences  bool
+	blockPrivateAddresses bool
+	autoLabelID           influxdb.ID
+}
+
+// NotificationEndpointBackendOptFn is a functional option for configuring the NotificationEndpointBackend.
+type NotificationEndpointBackendOptFn func(*NotificationEndpointBackend)
+
+// WithClientProvidedIDs lets clients choose the ID of the notification endpoints they create
+// by setting the id of the POST body, so provisioned endpoints keep stable IDs across environments.
+func WithClientProvidedIDs() NotificationEndpointBackendOptFn {
+	return func(b *NotificationEndpointBackend) {
+		b.clientProvidedIDs = true
+	}
+}
+
+// WithSecretReferencesHidden replaces the secret key references of the endpoints returned
+// to readers that are not admins of the endpoint's organization with "***".
+func WithSecretReferencesHidden() NotificationEndpointBackendOptFn {
+	return func(b *NotificationEndpointBackend) {
+		b.hideSecretReferences = true
+	}
+}
+
+// WithAutoLabel maps the label to every notification endpoint created through the handler,
+// e.g. to tag all the endpoints an org manages through the API.
+func WithAutoLabel(labelID influxdb.ID) NotificationEndpointBackendOptFn {
+	return func(b *NotificationEndpointBackend) {
+		b.autoLabelID = labelID
+	}
+}
+
+// WithBlockPrivateAddresses rejects notification endpoints whose urls resolve to loopback,
+// link-local or private addresses, so tenants of a shared host can't reach internal services
+// such as the cloud metadata address.
+func WithBlockPrivateAddresses() NotificationEndpointBackendOptFn {
+	return func(b *NotificationEndpointBackend) {
+		b.blockPrivateAddresses = true
+	}
 }
 
 // NewNotificationEndpointBackend returns a new instance of NotificationEndpointBackend.
-func NewNotificationEndpointBackend(log *zap.Logger, b *APIBackend) *NotificationEndpointBackend {
-	return &NotificationEndpointBackend{
+func NewNotificationEndpointBackend(log *zap.Logger, b *APIBackend, opts ...NotificationEndpointBackendOptFn) *NotificationEndpointBackend {
+	backend := &NotificationEndpointBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
@@ -40,7 +97,18 @@ func NewNotificationEndpointBackend(log *zap.Logger, b *APIBackend) *Notificatio
 		LabelService:                b.LabelService,
 		UserService:                 b.UserService,
 		OrganizationService:         b.OrganizationService,
-	}
+		SecretService:               b.SecretService,
+		ResourceOwnershipService:    b.ResourceOwnershipService,
+		GenerationService:           b.NotificationEndpointGenerationService,
+		HealthService:               b.NotificationEndpointHealthService,
+		HistoryService:              b.NotificationEndpointHistoryService,
+		NotificationRuleStore:       b.NotificationRuleStore,
+		EndpointSecretService:       b.NotificationEndpointSecretService,
+	}
+	for _, o := range opts {
+		o(backend)
+	}
+	return backend
 }
 
 func (b *NotificationEndpointBackend) Logger() *zap.Logger {
@@ -53,15 +121,41 @@ type NotificationEndpointHandler struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
+	// actionRouter serves the static collection routes (e.g. cloneToOrg), which
+	// httprouter refuses to register alongside the :id wildcard.
+	actionRouter *httprouter.Router
+
 	NotificationEndpointService influxdb.NotificationEndpointService
 	UserResourceMappingService  influxdb.UserResourceMappingService
 	LabelService                influxdb.LabelService
 	UserService                 influxdb.UserService
 	OrganizationService         influxdb.OrganizationService
+	SecretService               influxdb.SecretService
+	ResourceOwnershipService    influxdb.ResourceOwnershipService
+	GenerationService           influxdb.NotificationEndpointGenerationService
+	HealthService               influxdb.NotificationEndpointHealthService
+	HistoryService              influxdb.NotificationEndpointHistoryService
+	NotificationRuleStore       influxdb.NotificationRuleStore
+	EndpointSecretService       influxdb.NotificationEndpointSecretService
+
+	clientProvidedIDs     bool
+	hideSecretReferences  bool
+	blockPrivateAddresses bool
+	autoLabelID           influxdb.ID
+	// lookupIP resolves the hosts of endpoint urls when private addresses are blocked.
+	lookupIP endpoint.IPLookup
 }
 
 const (
 	prefixNotificationEndpoints          = "/api/v2/notificationEndpoints"
+	notificationEndpointsCloneToOrgPath  = "/api/v2/notificationEndpoints/cloneToOrg"
+	notificationEndpointsDiffPath        = "/api/v2/notificationEndpoints/diff"
+	notificationEndpointsExportPath      = "/api/v2/notificationEndpoints/export"
+	notificationEndpointsImportPath      = "/api/v2/notificationEndpoints/import"
+	notificationEndpointsTypesPath       = "/api/v2/notificationEndpoints/types"
+	notificationEndpointsDetectPath      = "/api/v2/notificationEndpoints/detect"
+	notificationEndpointsHealthPath      = "/api/v2/notificationEndpoints/health"
+	notificationEndpointsFromCurlPath    = "/api/v2/notificationEndpoints/fromCurl"
 	notificationEndpointsIDPath          = "/api/v2/notificationEndpoints/:id"
 	notificationEndpointsIDMembersPath   = "/api/v2/notificationEndpoints/:id/members"
 	notificationEndpointsIDMembersIDPath = "/api/v2/notificationEndpoints/:id/members/:userID"
@@ -69,6 +163,14 @@ const (
 	notificationEndpointsIDOwnersIDPath  = "/api/v2/notificationEndpoints/:id/owners/:userID"
 	notificationEndpointsIDLabelsPath    = "/api/v2/notificationEndpoints/:id/labels"
 	notificationEndpointsIDLabelsIDPath  = "/api/v2/notificationEndpoints/:id/labels/:lid"
+	notificationEndpointsIDRotateSecret  = "/api/v2/notificationEndpoints/:id/rotateSecret"
+	notificationEndpointsIDMutePath      = "/api/v2/notificationEndpoints/:id/mute"
+	notificationEndpointsIDUnmutePath    = "/api/v2/notificationEndpoints/:id/unmute"
+	notificationEndpointsIDExportPath    = "/api/v2/notificationEndpoints/:id/export"
+	notificationEndpointsIDPreviewPath   = "/api/v2/notificationEndpoints/:id/preview"
+	notificationEndpointsIDTransferPath  = "/api/v2/notificationEndpoints/:id/transferOwnership"
+	notificationEndpointsIDHistoryPath   = "/api/v2/notificationEndpoints/:id/history"
+	notificationEndpointsIDRestorePath   = "/api/v2/notificationEndpoints/:id/history/:version/restore"
 )
 
 // NewNotificationEndpointHandler returns a new instance of NotificationEndpointHandler.
@@ -77,12 +179,26 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 		Router:           NewRouter(b.HTTPErrorHandler),
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
+		actionRouter:     NewRouter(b.HTTPErrorHandler),
 
 		NotificationEndpointService: b.NotificationEndpointService,
 		UserResourceMappingService:  b.UserResourceMappingService,
 		LabelService:                b.LabelService,
 		UserService:                 b.UserService,
 		OrganizationService:         b.OrganizationService,
+		SecretService:               b.SecretService,
+		ResourceOwnershipService:    b.ResourceOwnershipService,
+		GenerationService:           b.GenerationService,
+		HealthService:               b.HealthService,
+		HistoryService:              b.HistoryService,
+		NotificationRuleStore:       b.NotificationRuleStore,
+		EndpointSecretService:       b.EndpointSecretService,
+
+		clientProvidedIDs:     b.clientProvidedIDs,
+		hideSecretReferences:  b.hideSecretReferences,
+		blockPrivateAddresses: b.blockPrivateAddresses,
+		autoLabelID:           b.autoLabelID,
+		lookupIP:              net.DefaultResolver.LookupIPAddr,
 	}
 	h.HandlerFunc("POST", prefixNotificationEndpoints, h.handlePostNotificationEndpoint)
 	h.HandlerFunc("GET", prefixNotificationEndpoints, h.handleGetNotificationEndpoints)
@@ -90,6 +206,23 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 	h.HandlerFunc("DELETE", notificationEndpointsIDPath, h.handleDeleteNotificationEndpoint)
 	h.HandlerFunc("PUT", notificationEndpointsIDPath, h.handlePutNotificationEndpoint)
 	h.HandlerFunc("PATCH", notificationEndpointsIDPath, h.handlePatchNotificationEndpoint)
+	h.HandlerFunc("POST", notificationEndpointsIDRotateSecret, h.handlePostNotificationEndpointRotateSecret)
+	h.HandlerFunc("POST", notificationEndpointsIDMutePath, h.handlePostNotificationEndpointMute)
+	h.HandlerFunc("POST", notificationEndpointsIDUnmutePath, h.handlePostNotificationEndpointUnmute)
+	h.HandlerFunc("GET", notificationEndpointsIDExportPath, h.handleGetNotificationEndpointExport)
+	h.HandlerFunc("POST", notificationEndpointsIDPreviewPath, h.handlePostNotificationEndpointPreview)
+	h.HandlerFunc("POST", notificationEndpointsIDTransferPath, h.handlePostNotificationEndpointTransferOwnership)
+	h.HandlerFunc("GET", notificationEndpointsIDHistoryPath, h.handleGetNotificationEndpointHistory)
+	h.HandlerFunc("POST", notificationEndpointsIDRestorePath, h.handlePostNotificationEndpointRestore)
+
+	h.actionRouter.HandlerFunc("POST", notificationEndpointsCloneToOrgPath, h.handleCloneNotificationEndpointsToOrg)
+	h.actionRouter.HandlerFunc("POST", notificationEndpointsDiffPath, h.handlePostNotificationEndpointsDiff)
+	h.actionRouter.HandlerFunc("GET", notificationEndpointsExportPath, h.handleGetNotificationEndpointsExport)
+	h.actionRouter.HandlerFunc("POST", notificationEndpointsImportPath, h.handlePostNotificationEndpointsImport)
+	h.actionRouter.HandlerFunc("GET", notificationEndpointsTypesPath, h.handleGetNotificationEndpointTypes)
+	h.actionRouter.HandlerFunc("POST", notificationEndpointsDetectPath, h.handlePostNotificationEndpointsDetect)
+	h.actionRouter.HandlerFunc("GET", notificationEndpointsHealthPath, h.handleGetNotificationEndpointsHealth)
+	h.actionRouter.HandlerFunc("POST", notificationEndpointsFromCurlPath, h.handlePostNotificationEndpointFromCurl)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -125,9 +258,53 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 	h.HandlerFunc("POST", notificationEndpointsIDLabelsPath, newPostLabelHandler(labelBackend))
 	h.HandlerFunc("DELETE", notificationEndpointsIDLabelsIDPath, newDeleteLabelHandler(labelBackend))
 
+	// the routers set the Allow header to the methods registered for the path
+	// before calling the OPTIONS handler.
+	h.Router.GlobalOPTIONS = http.HandlerFunc(handleOptions)
+	h.actionRouter.GlobalOPTIONS = http.HandlerFunc(handleOptions)
+
 	return h
 }
 
+// ServeHTTP tags every request with a request ID before routing it.
+func (h *NotificationEndpointHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var next http.Handler = h.Router
+	if routerHandles(h.actionRouter, r) {
+		next = h.actionRouter
+	}
+	requestIDMW(next).ServeHTTP(w, r)
+}
+
+// routerHandles reports whether the router has a handler for the request. An
+// OPTIONS request is handled by the router serving any method on its path.
+func routerHandles(router *httprouter.Router, r *http.Request) bool {
+	methods := []string{r.Method}
+	if r.Method == http.MethodOptions {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	for _, method := range methods {
+		if handle, _, _ := router.Lookup(method, r.URL.Path); handle != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// handleOptions answers an OPTIONS request with no content, leaving the Allow header
+// set by the router.
+func handleOptions(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// logger returns the handler logger annotated with the request ID.
+func (h *NotificationEndpointHandler) logger(r *http.Request) *zap.Logger {
+	reqID := middleware.GetReqID(r.Context())
+	if reqID == "" {
+		reqID = r.Header.Get(RequestIDHeader)
+	}
+	return h.log.With(zap.String("request_id", reqID))
+}
+
 type notificationEndpointLinks struct {
 	Self    string `json:"self"`
 	Labels  string `json:"labels"`
@@ -137,13 +314,25 @@ type notificationEndpointLinks struct {
 
 type postNotificationEndpointRequest struct {
 	influxdb.NotificationEndpoint
-	Labels []string `json:"labels"`
+	Labels              []string `json:"labels"`
+	LabelNames          []string `json:"labelNames"`
+	IfNotExists         bool     `json:"-"`
+	CreateMissingLabels bool     `json:"-"`
 }
 
 type notificationEndpointResponse struct {
 	influxdb.NotificationEndpoint
 	Labels []influxdb.Label          `json:"labels"`
 	Links  notificationEndpointLinks `json:"links"`
+	// Warnings are only reported in create and update responses.
+	Warnings []string `json:"warnings,omitempty"`
+	// Members and Owners are only embedded when requested with the include query param.
+	Members []*resourceUserResponse `json:"members,omitempty"`
+	Owners  []*resourceUserResponse `json:"owners,omitempty"`
+
+	hideSecretReferences bool
+	// fields projects the response to these top-level fields when set.
+	fields map[string]bool
 }
 
 func (resp notificationEndpointResponse) MarshalJSON() ([]byte, error) {
@@ -151,19 +340,210 @@ func (resp notificationEndpointResponse) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if resp.hideSecretReferences {
+		if b1, err = endpoint.MaskSecretReferences(resp.NotificationEndpoint, b1); err != nil {
+			return nil, err
+		}
+	}
+	if b1, err = omitZeroTimestamps(resp.NotificationEndpoint.GetCRUDLog(), b1); err != nil {
+		return nil, err
+	}
 
-	b2, err := json.Marshal(struct {
-		Labels []influxdb.Label          `json:"labels"`
-		Links  notificationEndpointLinks `json:"links"`
+	related := struct {
+		Labels   []influxdb.Label          `json:"labels"`
+		Links    notificationEndpointLinks `json:"links"`
+		Warnings []string                  `json:"warnings,omitempty"`
+		Members  interface{}               `json:"members,omitempty"`
+		Owners   interface{}               `json:"owners,omitempty"`
 	}{
-		Links:  resp.Links,
-		Labels: resp.Labels,
-	})
+		Links:    resp.Links,
+		Labels:   resp.Labels,
+		Warnings: resp.Warnings,
+	}
+	// embedded collections are kept in the response even when empty.
+	if resp.Members != nil {
+		related.Members = resp.Members
+	}
+	if resp.Owners != nil {
+		related.Owners = resp.Owners
+	}
+	b2, err := json.Marshal(related)
 	if err != nil {
 		return nil, err
 	}
 
-	return []byte(string(b1[:len(b1)-1]) + ", " + string(b2[1:])), nil
+	b := []byte(string(b1[:len(b1)-1]) + ", " + string(b2[1:]))
+	if len(resp.fields) == 0 {
+		return b, nil
+	}
+	return projectJSONFields(b, resp.fields)
+}
+
+// omitZeroTimestamps removes the createdAt and updatedAt fields that are not set from
+// the json encoded resource, rather than reporting them as the zero time.
+func omitZeroTimestamps(log influxdb.CRUDLog, b []byte) ([]byte, error) {
+	if !log.CreatedAt.IsZero() && !log.UpdatedAt.IsZero() {
+		return b, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	if log.CreatedAt.IsZero() {
+		delete(fields, "createdAt")
+	}
+	if log.UpdatedAt.IsZero() {
+		delete(fields, "updatedAt")
+	}
+	return json.Marshal(fields)
+}
+
+// projectJSONFields keeps only the given top-level fields of a json object.
+func projectJSONFields(b []byte, fields map[string]bool) ([]byte, error) {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	projected := make(map[string]json.RawMessage, len(fields))
+	for k, v := range all {
+		if fields[k] {
+			projected[k] = v
+		}
+	}
+	return json.Marshal(projected)
+}
+
+// decodeNotificationEndpointFields decodes the comma separated fields query param.
+func decodeNotificationEndpointFields(r *http.Request) map[string]bool {
+	v := r.URL.Query().Get("fields")
+	if v == "" {
+		return nil
+	}
+	fields := make(map[string]bool)
+	for _, f := range strings.Split(v, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields[f] = true
+		}
+	}
+	return fields
+}
+
+// jsonAPIMediaType is the media type of JSON:API documents, clients request the
+// JSON:API representation of notification endpoints by accepting it.
+const jsonAPIMediaType = "application/vnd.api+json"
+
+// acceptsJSONAPI reports whether the request accepts JSON:API documents.
+func acceptsJSONAPI(r *http.Request) bool {
+	for _, v := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err == nil && mt == jsonAPIMediaType {
+			return true
+		}
+	}
+	return false
+}
+
+type (
+	jsonAPIDocument struct {
+		Data  interface{}           `json:"data"`
+		Links *influxdb.PagingLinks `json:"links,omitempty"`
+	}
+
+	jsonAPIResource struct {
+		Type          string                         `json:"type"`
+		ID            string                         `json:"id"`
+		Attributes    map[string]json.RawMessage     `json:"attributes"`
+		Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+		Links         map[string]string              `json:"links,omitempty"`
+	}
+
+	jsonAPIRelationship struct {
+		// Data is only set for the relationships embedded in the response.
+		Data  interface{}       `json:"data,omitempty"`
+		Links map[string]string `json:"links,omitempty"`
+	}
+
+	jsonAPIResourceIdentifier struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+)
+
+// newNotificationEndpointJSONAPIResource reshapes the notification endpoint response into
+// a JSON:API resource object. JSON:API reserves the type member, so the endpoint type is
+// reported as the endpointType attribute.
+func newNotificationEndpointJSONAPIResource(resp notificationEndpointResponse) (jsonAPIResource, error) {
+	fields := resp.fields
+	resp.fields = nil
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return jsonAPIResource{}, err
+	}
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(b, &attrs); err != nil {
+		return jsonAPIResource{}, err
+	}
+	for _, k := range []string{"id", "labels", "links", "members", "owners", "warnings"} {
+		delete(attrs, k)
+	}
+	if typ, ok := attrs["type"]; ok {
+		delete(attrs, "type")
+		attrs["endpointType"] = typ
+	}
+	if len(fields) > 0 {
+		for k := range attrs {
+			if !fields[k] && !(k == "endpointType" && fields["type"]) {
+				delete(attrs, k)
+			}
+		}
+	}
+
+	labels := make([]jsonAPIResourceIdentifier, 0, len(resp.Labels))
+	for _, l := range resp.Labels {
+		labels = append(labels, jsonAPIResourceIdentifier{Type: "labels", ID: l.ID.String()})
+	}
+	rels := map[string]jsonAPIRelationship{
+		"labels": {
+			Data:  labels,
+			Links: map[string]string{"related": resp.Links.Labels},
+		},
+		"members": {
+			Data:  jsonAPIUserIdentifiers(resp.Members),
+			Links: map[string]string{"related": resp.Links.Members},
+		},
+		"owners": {
+			Data:  jsonAPIUserIdentifiers(resp.Owners),
+			Links: map[string]string{"related": resp.Links.Owners},
+		},
+	}
+
+	return jsonAPIResource{
+		Type:          "notificationEndpoints",
+		ID:            resp.NotificationEndpoint.GetID().String(),
+		Attributes:    attrs,
+		Relationships: rels,
+		Links:         map[string]string{"self": resp.Links.Self},
+	}, nil
+}
+
+// jsonAPIUserIdentifiers returns the resource identifiers of embedded users, it returns
+// nil when the users are not embedded so the relationship only carries links.
+func jsonAPIUserIdentifiers(users []*resourceUserResponse) interface{} {
+	if users == nil {
+		return nil
+	}
+	ids := make([]jsonAPIResourceIdentifier, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, jsonAPIResourceIdentifier{Type: "users", ID: u.ID.String()})
+	}
+	return ids
+}
+
+// encodeJSONAPIResponse writes the JSON:API document with the JSON:API media type.
+func encodeJSONAPIResponse(w http.ResponseWriter, code int, doc jsonAPIDocument) error {
+	w.Header().Set("Content-Type", jsonAPIMediaType)
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(doc)
 }
 
 type notificationEndpointsResponse struct {
@@ -171,7 +551,9 @@ type notificationEndpointsResponse struct {
 	Links                 *influxdb.PagingLinks          `json:"links"`
 }
 
-func newNotificationEndpointResponse(edp influxdb.NotificationEndpoint, labels []*influxdb.Label) notificationEndpointResponse {
+// newNotificationEndpointResponse hides the secret key references of the endpoint from
+// readers that are not admins of its org, see WithSecretReferencesHidden.
+func (h *NotificationEndpointHandler) newNotificationEndpointResponse(ctx context.Context, edp influxdb.NotificationEndpoint, labels []*influxdb.Label) notificationEndpointResponse {
 	res := notificationEndpointResponse{
 		NotificationEndpoint: edp,
 		Links: notificationEndpointLinks{
@@ -180,7 +562,8 @@ func newNotificationEndpointResponse(edp influxdb.NotificationEndpoint, labels [
 			Members: fmt.Sprintf("/api/v2/notificationEndpoints/%s/members", edp.GetID()),
 			Owners:  fmt.Sprintf("/api/v2/notificationEndpoints/%s/owners", edp.GetID()),
 		},
-		Labels: []influxdb.Label{},
+		Labels:               []influxdb.Label{},
+		hideSecretReferences: h.hidesSecretReferences(ctx, edp.GetOrgID()),
 	}
 
 	for _, l := range labels {
@@ -190,14 +573,14 @@ func newNotificationEndpointResponse(edp influxdb.NotificationEndpoint, labels [
 	return res
 }
 
-func newNotificationEndpointsResponse(ctx context.Context, edps []influxdb.NotificationEndpoint, labelService influxdb.LabelService, f influxdb.PagingFilter, opts influxdb.FindOptions) *notificationEndpointsResponse {
+func (h *NotificationEndpointHandler) newNotificationEndpointsResponse(ctx context.Context, edps []influxdb.NotificationEndpoint, f influxdb.PagingFilter, opts influxdb.FindOptions) *notificationEndpointsResponse {
 	resp := &notificationEndpointsResponse{
 		NotificationEndpoints: make([]notificationEndpointResponse, len(edps)),
 		Links:                 newPagingLinks(prefixNotificationEndpoints, opts, f, len(edps)),
 	}
 	for i, edp := range edps {
-		labels, _ := labelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
-		resp.NotificationEndpoints[i] = newNotificationEndpointResponse(edp, labels)
+		labels, _ := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
+		resp.NotificationEndpoints[i] = h.newNotificationEndpointResponse(ctx, edp, labels)
 	}
 	return resp
 }
@@ -222,19 +605,199 @@ func (h *NotificationEndpointHandler) handleGetNotificationEndpoints(w http.Resp
 	ctx := r.Context()
 	filter, opts, err := decodeNotificationEndpointFilter(ctx, r)
 	if err != nil {
-		h.log.Debug("Failed to decode request", zap.Error(err))
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	strictPaging, err := decodeStrictPaging(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	idsOnly, err := decodeIDsOnly(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	edps, n, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, filter, opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	var orgID string
+	if filter.OrgID != nil {
+		orgID = filter.OrgID.String()
+	}
+	h.logger(r).Debug("NotificationEndpoints retrieved", zap.String("orgID", orgID), zap.String("notificationEndpoints", fmt.Sprint(edps)))
+
+	// an empty page past the first one means the offset is at or beyond the total.
+	overPaged := len(edps) == 0 && opts.Offset > 0
+	if overPaged && strictPaging {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("offset %d is out of range", opts.Offset),
+		}, w)
+		return
+	}
+
+	// mirror the paging links in headers for clients that stream the body.
+	w.Header().Set("X-Total-Count", strconv.Itoa(n))
+	w.Header().Set("X-Page-Limit", strconv.Itoa(opts.Limit))
+	w.Header().Set("X-Page-Offset", strconv.Itoa(opts.Offset))
+	if h.GenerationService != nil && filter.OrgID != nil {
+		p := influxdb.Permission{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: filter.OrgID},
+		}
+		if err := authorizer.IsAllowed(ctx, p); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		gen, err := h.GenerationService.FindNotificationEndpointGeneration(ctx, *filter.OrgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		w.Header().Set("X-Endpoints-Generation", strconv.FormatUint(gen, 10))
+	}
+
+	// the ids only listing skips the label lookup of every endpoint, paging is left to the headers.
+	if idsOnly {
+		resp := notificationEndpointIDsResponse{IDs: make([]influxdb.ID, 0, len(edps))}
+		for _, edp := range edps {
+			resp.IDs = append(resp.IDs, edp.GetID())
+		}
+		if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+			logEncodingError(h.logger(r), r, err)
+		}
+		return
+	}
+
+	resp := h.newNotificationEndpointsResponse(ctx, edps, filter, opts)
+	if overPaged {
+		resp.Links.Next = ""
+	}
+	fields := decodeNotificationEndpointFields(r)
+	for i := range resp.NotificationEndpoints {
+		resp.NotificationEndpoints[i].fields = fields
+	}
+	if acceptsJSONAPI(r) {
+		data := make([]jsonAPIResource, 0, len(resp.NotificationEndpoints))
+		for _, edpResp := range resp.NotificationEndpoints {
+			res, err := newNotificationEndpointJSONAPIResource(edpResp)
+			if err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+			data = append(data, res)
+		}
+		if err := encodeJSONAPIResponse(w, http.StatusOK, jsonAPIDocument{Data: data, Links: resp.Links}); err != nil {
+			logEncodingError(h.logger(r), r, err)
+		}
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type notificationEndpointIDsResponse struct {
+	IDs []influxdb.ID `json:"ids"`
+}
+
+type notificationEndpointTypesResponse struct {
+	Types []endpoint.TypeInfo `json:"types"`
+}
+
+// handleGetNotificationEndpointTypes is the HTTP handler for the GET /api/v2/notificationEndpoints/types route.
+func (h *NotificationEndpointHandler) handleGetNotificationEndpointTypes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := notificationEndpointTypesResponse{
+		Types: endpoint.Types(),
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type notificationEndpointsHealthResponse struct {
+	Endpoints []influxdb.NotificationEndpointHealth `json:"endpoints"`
+}
+
+// handleGetNotificationEndpointsHealth is the HTTP handler for the GET /api/v2/notificationEndpoints/health route.
+// It runs a connectivity check of the org's active endpoints.
+func (h *NotificationEndpointHandler) handleGetNotificationEndpointsHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var orgID influxdb.ID
+	if err := orgID.DecodeFromString(r.URL.Query().Get("orgID")); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID must be provided",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	p := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.NotificationEndpointResourceType,
+			OrgID: &orgID,
+		},
+	}
+	if err := authorizer.IsAllowed(ctx, p); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	health, err := h.HealthService.CheckAll(ctx, orgID)
+	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	edps, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, filter, opts)
+	if err := encodeResponse(ctx, w, http.StatusOK, notificationEndpointsHealthResponse{Endpoints: health}); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type detectNotificationEndpointRequest struct {
+	URL string `json:"url"`
+}
+
+type detectNotificationEndpointResponse struct {
+	endpoint.TypeInfo
+	// Detected is false when the url does not belong to a known provider.
+	Detected bool `json:"detected"`
+}
+
+// handlePostNotificationEndpointsDetect is the HTTP handler for the POST /api/v2/notificationEndpoints/detect route.
+// It guesses the endpoint type of a webhook receiver url so that UIs can pre-select it.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointsDetect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req detectNotificationEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	info, detected, err := endpoint.Detect(req.URL)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("NotificationEndpoints retrieved", zap.String("notificationEndpoints", fmt.Sprint(edps)))
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newNotificationEndpointsResponse(ctx, edps, h.LabelService, filter, opts)); err != nil {
-		logEncodingError(h.log, r, err)
+	resp := detectNotificationEndpointResponse{
+		TypeInfo: info,
+		Detected: detected,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
 		return
 	}
 }
@@ -246,12 +809,17 @@ func (h *NotificationEndpointHandler) handleGetNotificationEndpoint(w http.Respo
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	include, err := decodeNotificationEndpointInclude(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
 	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("NotificationEndpoint retrieved", zap.String("notificationEndpoint", fmt.Sprint(edp)))
+	h.logger(r).Debug("NotificationEndpoint retrieved", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(edp)))
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
 	if err != nil {
@@ -259,12 +827,98 @@ func (h *NotificationEndpointHandler) handleGetNotificationEndpoint(w http.Respo
 		return
 	}
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newNotificationEndpointResponse(edp, labels)); err != nil {
-		logEncodingError(h.log, r, err)
+	resp := h.newNotificationEndpointResponse(ctx, edp, labels)
+	resp.fields = decodeNotificationEndpointFields(r)
+	if include["members"] {
+		if resp.Members, err = h.findNotificationEndpointUsers(ctx, edp.GetID(), influxdb.Member); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	if include["owners"] {
+		if resp.Owners, err = h.findNotificationEndpointUsers(ctx, edp.GetID(), influxdb.Owner); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	if acceptsJSONAPI(r) {
+		res, err := newNotificationEndpointJSONAPIResource(resp)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if err := encodeJSONAPIResponse(w, http.StatusOK, jsonAPIDocument{Data: res}); err != nil {
+			logEncodingError(h.logger(r), r, err)
+		}
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
 		return
 	}
 }
 
+// decodeNotificationEndpointInclude decodes the comma separated include query param
+// naming the related collections to embed in the response. Labels are always embedded.
+func decodeNotificationEndpointInclude(r *http.Request) (map[string]bool, error) {
+	include := make(map[string]bool)
+	v := r.URL.Query().Get("include")
+	if v == "" {
+		return include, nil
+	}
+	for _, c := range strings.Split(v, ",") {
+		switch c = strings.TrimSpace(c); c {
+		case "members", "owners", "labels":
+			include[c] = true
+		case "":
+		default:
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("unsupported include %q, must be one of members, owners or labels", c),
+			}
+		}
+	}
+	return include, nil
+}
+
+// findNotificationEndpointUsers returns the users of the given type of the notification endpoint.
+func (h *NotificationEndpointHandler) findNotificationEndpointUsers(ctx context.Context, id influxdb.ID, userType influxdb.UserType) ([]*resourceUserResponse, error) {
+	mappings, _, err := h.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   id,
+		ResourceType: influxdb.NotificationEndpointResourceType,
+		UserType:     userType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*resourceUserResponse, 0, len(mappings))
+	for _, m := range mappings {
+		if m.MappingType == influxdb.OrgMappingType {
+			continue
+		}
+		u, err := h.UserService.FindUserByID(ctx, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, newResourceUserResponse(u, userType))
+	}
+	return users, nil
+}
+
+// hidesSecretReferences reports whether the secret key references of the org's endpoints
+// must be hidden from the reader, which is the case for anyone but the org's admins.
+func (h *NotificationEndpointHandler) hidesSecretReferences(ctx context.Context, orgID influxdb.ID) bool {
+	if !h.hideSecretReferences {
+		return false
+	}
+	p := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &orgID},
+	}
+	return authorizer.IsAllowed(ctx, p) != nil
+}
+
 func decodeNotificationEndpointFilter(ctx context.Context, r *http.Request) (influxdb.NotificationEndpointFilter, influxdb.FindOptions, error) {
 	f := influxdb.NotificationEndpointFilter{
 		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
@@ -300,58 +954,193 @@ func decodeNotificationEndpointFilter(ctx context.Context, r *http.Request) (inf
 		f.UserID = *id
 	}
 
+	if groupID := q.Get("group"); groupID != "" {
+		id, err := influxdb.IDFromString(groupID)
+		if err != nil {
+			return influxdb.NotificationEndpointFilter{}, influxdb.FindOptions{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "group is invalid",
+				Err:  err,
+			}
+		}
+		f.GroupID = id
+	}
+
+	if label := q.Get("excludeLabel"); label != "" {
+		f.ExcludeLabel = &label
+	}
+
+	if name := q.Get("name"); name != "" {
+		f.Name = &name
+	}
+
 	return f, *opts, err
 }
 
-func decodePostNotificationEndpointRequest(r *http.Request) (postNotificationEndpointRequest, error) {
-	b, err := ioutil.ReadAll(r.Body)
+// decodeStrictPaging reads the strictPaging query param, which turns an out of
+// range offset into an error instead of an empty page.
+func decodeStrictPaging(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("strictPaging")
+	if v == "" {
+		return false, nil
+	}
+	strict, err := strconv.ParseBool(v)
 	if err != nil {
-		return postNotificationEndpointRequest{}, &influxdb.Error{
+		return false, &influxdb.Error{
 			Code: influxdb.EInvalid,
-			Err:  err,
+			Msg:  "strictPaging is invalid",
 		}
 	}
-	defer r.Body.Close()
-	edp, err := endpoint.UnmarshalJSON(b)
+	return strict, nil
+}
+
+func decodeIDsOnly(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("idsOnly")
+	if v == "" {
+		return false, nil
+	}
+	idsOnly, err := strconv.ParseBool(v)
 	if err != nil {
-		return postNotificationEndpointRequest{}, &influxdb.Error{
+		return false, &influxdb.Error{
 			Code: influxdb.EInvalid,
-			Err:  err,
+			Msg:  "idsOnly is invalid",
 		}
 	}
+	return idsOnly, nil
+}
 
-	var dl decodeLabels
-	if err := json.Unmarshal(b, &dl); err != nil {
-		return postNotificationEndpointRequest{}, &influxdb.Error{
+// readNotificationEndpointBody reads the whole request body, decompressing it
+// when it is gzip encoded.
+func readNotificationEndpointBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+
+	var in io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  errInvalidGzipHeader,
+				Err:  err,
+			}
+		}
+		defer gr.Close()
+		in = gr
+	}
+
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Err:  err,
 		}
 	}
-
-	return postNotificationEndpointRequest{
-		NotificationEndpoint: edp,
-		Labels:               dl.Labels,
-	}, nil
+	return b, nil
 }
 
-func decodePutNotificationEndpointRequest(ctx context.Context, r *http.Request) (influxdb.NotificationEndpoint, error) {
-	buf := new(bytes.Buffer)
-	if _, err := buf.ReadFrom(r.Body); err != nil {
+// decodeNotificationEndpoint decodes a notification endpoint from a request body.
+// The handlers call Base on the result, so a nil endpoint is rejected here rather
+// than left to panic further down.
+func decodeNotificationEndpoint(b []byte) (influxdb.NotificationEndpoint, error) {
+	edp, err := endpoint.UnmarshalJSON(b)
+	if err != nil {
 		return nil, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Err:  err,
 		}
 	}
-	defer r.Body.Close()
+	if endpoint.IsNil(edp) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "notification endpoint is missing",
+		}
+	}
+	return edp, nil
+}
+
+func decodePostNotificationEndpointRequest(r *http.Request) (postNotificationEndpointRequest, error) {
+	b, err := readNotificationEndpointBody(r)
+	if err != nil {
+		return postNotificationEndpointRequest{}, err
+	}
+
+	var strict bool
+	if v := r.URL.Query().Get("strict"); v != "" {
+		strict, err = strconv.ParseBool(v)
+		if err != nil {
+			return postNotificationEndpointRequest{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "strict must be a boolean",
+				Err:  err,
+			}
+		}
+	}
 
-	edp, err := endpoint.UnmarshalJSON(buf.Bytes())
+	var edp influxdb.NotificationEndpoint
+	if strict {
+		edp, err = endpoint.UnmarshalJSONStrict(b, "labels", "labelNames")
+	} else {
+		edp, err = decodeNotificationEndpoint(b)
+	}
 	if err != nil {
-		return nil, &influxdb.Error{
+		return postNotificationEndpointRequest{}, err
+	}
+
+	var dl struct {
+		Labels     []string `json:"labels"`
+		LabelNames []string `json:"labelNames"`
+	}
+	if err := json.Unmarshal(b, &dl); err != nil {
+		return postNotificationEndpointRequest{}, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Err:  err,
 		}
 	}
 
+	var ifNotExists bool
+	if v := r.URL.Query().Get("ifNotExists"); v != "" {
+		ifNotExists, err = strconv.ParseBool(v)
+		if err != nil {
+			return postNotificationEndpointRequest{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "ifNotExists must be a boolean",
+				Err:  err,
+			}
+		}
+	}
+
+	var createMissingLabels bool
+	if v := r.URL.Query().Get("createMissingLabels"); v != "" {
+		createMissingLabels, err = strconv.ParseBool(v)
+		if err != nil {
+			return postNotificationEndpointRequest{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "createMissingLabels must be a boolean",
+				Err:  err,
+			}
+		}
+	}
+
+	return postNotificationEndpointRequest{
+		NotificationEndpoint: edp,
+		Labels:               dl.Labels,
+		LabelNames:           dl.LabelNames,
+		IfNotExists:          ifNotExists,
+		CreateMissingLabels:  createMissingLabels,
+	}, nil
+}
+
+func decodePutNotificationEndpointRequest(ctx context.Context, r *http.Request) (influxdb.NotificationEndpoint, error) {
+	b, err := readNotificationEndpointBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	edp, err := decodeNotificationEndpoint(b)
+	if err != nil {
+		return nil, err
+	}
+
 	params := httprouter.ParamsFromContext(ctx)
 	i, err := influxdb.IDFromString(params.ByName("id"))
 	if err != nil {
@@ -399,7 +1188,7 @@ func (h *NotificationEndpointHandler) handlePostNotificationEndpoint(w http.Resp
 	ctx := r.Context()
 	edp, err := decodePostNotificationEndpointRequest(r)
 	if err != nil {
-		h.log.Debug("Failed to decode request", zap.Error(err))
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
@@ -410,22 +1199,199 @@ func (h *NotificationEndpointHandler) handlePostNotificationEndpoint(w http.Resp
 		return
 	}
 
+	if edp.IfNotExists {
+		// normalize first so the name matches the one the endpoint would be stored with.
+		edp.Normalize()
+		existing, err := h.findNotificationEndpointByName(ctx, edp.GetOrgID(), edp.GetName())
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if existing != nil {
+			labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: existing.GetID()})
+			if err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+			h.logger(r).Debug("NotificationEndpoint already exists", zap.String("orgID", existing.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(existing)))
+			if err := encodeResponse(ctx, w, http.StatusOK, h.newNotificationEndpointResponse(ctx, existing, labels)); err != nil {
+				logEncodingError(h.logger(r), r, err)
+			}
+			return
+		}
+	}
+
+	labelIDs, err := h.resolveLabelNames(ctx, edp.GetOrgID(), edp.LabelNames, edp.CreateMissingLabels)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if id := edp.GetID(); h.clientProvidedIDs && id.Valid() {
+		ctx = influxdb.WithClientProvidedNotificationEndpointID(ctx, id)
+	}
+
+	if err := h.checkPublicAddresses(ctx, edp.NotificationEndpoint); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
 	err = h.NotificationEndpointService.CreateNotificationEndpoint(ctx, edp.NotificationEndpoint, auth.GetUserID())
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
 
-	labels := h.mapNewNotificationEndpointLabels(ctx, edp.NotificationEndpoint, edp.Labels)
+	labelIDs = append(edp.Labels, labelIDs...)
+	if h.autoLabelID.Valid() && !containsString(labelIDs, h.autoLabelID.String()) {
+		labelIDs = append(labelIDs, h.autoLabelID.String())
+	}
+	labels := h.mapNewNotificationEndpointLabels(ctx, edp.NotificationEndpoint, labelIDs)
+
+	h.logger(r).Debug("NotificationEndpoint created", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(edp)))
+
+	resp := h.newNotificationEndpointResponse(ctx, edp, labels)
+	resp.Warnings = endpoint.Warnings(edp.NotificationEndpoint)
+	if err := encodeResponse(ctx, w, http.StatusCreated, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type postNotificationEndpointFromCurlRequest struct {
+	Curl        string          `json:"curl"`
+	OrgID       influxdb.ID     `json:"orgID"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Status      influxdb.Status `json:"status"`
+}
+
+// handlePostNotificationEndpointFromCurl is the HTTP handler for the POST /api/v2/notificationEndpoints/fromCurl route.
+// It creates an http endpoint from a curl command, so users can paste what they tested in a shell.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointFromCurl(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req postNotificationEndpointFromCurlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}, w)
+		return
+	}
+	if !req.OrgID.Valid() {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID must be provided",
+		}, w)
+		return
+	}
+	if req.Status == "" {
+		req.Status = influxdb.Active
+	}
+
+	edp, err := endpoint.ParseCurl(req.Curl)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	edp.OrgID = &req.OrgID
+	edp.Name = req.Name
+	edp.Description = req.Description
+	edp.Status = req.Status
+	if err := h.checkPublicAddresses(ctx, edp); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.NotificationEndpointService.CreateNotificationEndpoint(ctx, edp, auth.GetUserID()); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
 
-	h.log.Debug("NotificationEndpoint created", zap.String("notificationEndpoint", fmt.Sprint(edp)))
+	var labelIDs []string
+	if h.autoLabelID.Valid() {
+		labelIDs = append(labelIDs, h.autoLabelID.String())
+	}
+	labels := h.mapNewNotificationEndpointLabels(ctx, edp, labelIDs)
+	h.logger(r).Debug("NotificationEndpoint created from curl", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(edp)))
 
-	if err := encodeResponse(ctx, w, http.StatusCreated, newNotificationEndpointResponse(edp, labels)); err != nil {
-		logEncodingError(h.log, r, err)
+	resp := h.newNotificationEndpointResponse(ctx, edp, labels)
+	resp.Warnings = endpoint.Warnings(edp)
+	if err := encodeResponse(ctx, w, http.StatusCreated, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
 		return
 	}
 }
 
+// findNotificationEndpointByName returns the notification endpoint with the given
+// name in the org, or nil if there is none.
+func (h *NotificationEndpointHandler) findNotificationEndpointByName(ctx context.Context, orgID influxdb.ID, name string) (influxdb.NotificationEndpoint, error) {
+	edps, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &orgID,
+		Name:  &name,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, edp := range edps {
+		if edp.GetName() == name {
+			return edp, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveLabelNames returns the IDs of the org's labels with the given names. Missing labels
+// are created when createMissing is set, otherwise they are an error.
+func (h *NotificationEndpointHandler) resolveLabelNames(ctx context.Context, orgID influxdb.ID, names []string, createMissing bool) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	existing, err := h.LabelService.FindLabels(ctx, influxdb.LabelFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]influxdb.ID, len(existing))
+	for _, l := range existing {
+		byName[l.Name] = l.ID
+	}
+
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if id, ok := byName[name]; ok {
+			ids = append(ids, id.String())
+			continue
+		}
+		if !createMissing {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  fmt.Sprintf("label %q not found", name),
+			}
+		}
+
+		l := &influxdb.Label{
+			OrgID: orgID,
+			Name:  name,
+		}
+		if err := h.LabelService.CreateLabel(ctx, l); err != nil {
+			return nil, err
+		}
+		byName[name] = l.ID
+		ids = append(ids, l.ID.String())
+	}
+	return ids, nil
+}
+
 func (h *NotificationEndpointHandler) mapNewNotificationEndpointLabels(ctx context.Context, nre influxdb.NotificationEndpoint, labels []string) []*influxdb.Label {
 	var ls []*influxdb.Label
 	for _, sid := range labels {
@@ -457,12 +1423,34 @@ func (h *NotificationEndpointHandler) mapNewNotificationEndpointLabels(ctx conte
 	return ls
 }
 
+// checkPublicAddresses rejects endpoints sending to private addresses when the handler
+// is configured with WithBlockPrivateAddresses.
+func (h *NotificationEndpointHandler) checkPublicAddresses(ctx context.Context, edp influxdb.NotificationEndpoint) error {
+	if !h.blockPrivateAddresses {
+		return nil
+	}
+	return endpoint.CheckPublicAddresses(ctx, edp, h.lookupIP)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // handlePutNotificationEndpoint is the HTTP handler for the PUT /api/v2/notificationEndpoints route.
 func (h *NotificationEndpointHandler) handlePutNotificationEndpoint(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	edp, err := decodePutNotificationEndpointRequest(ctx, r)
 	if err != nil {
-		h.log.Debug("Failed to decode request", zap.Error(err))
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.checkPublicAddresses(ctx, edp); err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
@@ -483,10 +1471,12 @@ func (h *NotificationEndpointHandler) handlePutNotificationEndpoint(w http.Respo
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("NotificationEndpoint replaced", zap.String("notificationEndpoint", fmt.Sprint(edp)))
+	h.logger(r).Debug("NotificationEndpoint replaced", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(edp)))
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newNotificationEndpointResponse(edp, labels)); err != nil {
-		logEncodingError(h.log, r, err)
+	resp := h.newNotificationEndpointResponse(ctx, edp, labels)
+	resp.Warnings = endpoint.Warnings(edp)
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
 		return
 	}
 }
@@ -496,7 +1486,7 @@ func (h *NotificationEndpointHandler) handlePatchNotificationEndpoint(w http.Res
 	ctx := r.Context()
 	req, err := decodePatchNotificationEndpointRequest(ctx, r)
 	if err != nil {
-		h.log.Debug("Failed to decode request", zap.Error(err))
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
@@ -512,10 +1502,12 @@ func (h *NotificationEndpointHandler) handlePatchNotificationEndpoint(w http.Res
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("NotificationEndpoint patch", zap.String("notificationEndpoint", fmt.Sprint(edp)))
+	h.logger(r).Debug("NotificationEndpoint patch", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(edp)))
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newNotificationEndpointResponse(edp, labels)); err != nil {
-		logEncodingError(h.log, r, err)
+	resp := h.newNotificationEndpointResponse(ctx, edp, labels)
+	resp.Warnings = endpoint.Warnings(edp)
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
 		return
 	}
 }
@@ -528,6 +1520,57 @@ func (h *NotificationEndpointHandler) handleDeleteNotificationEndpoint(w http.Re
 		return
 	}
 
+	if v := r.URL.Query().Get("keepSecrets"); v != "" {
+		keep, err := strconv.ParseBool(v)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "keepSecrets must be a boolean",
+				Err:  err,
+			}, w)
+			return
+		}
+		if keep {
+			ctx = influxdb.KeepNotificationEndpointSecrets(ctx)
+		}
+	}
+
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, i)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if endpoint.DeleteProtected(edp) {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("notification endpoint %q is delete protected, clear deleteProtected to delete it", edp.GetName()),
+		}, w)
+		return
+	}
+
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "dryRun must be a boolean",
+				Err:  err,
+			}, w)
+			return
+		}
+		if dryRun {
+			plan, err := h.planNotificationEndpointDelete(ctx, i)
+			if err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+			if err := encodeResponse(ctx, w, http.StatusOK, plan); err != nil {
+				logEncodingError(h.logger(r), r, err)
+			}
+			return
+		}
+	}
+
 	flds, _, err := h.NotificationEndpointService.DeleteNotificationEndpoint(ctx, i)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
@@ -544,108 +1587,1600 @@ func (h *NotificationEndpointHandler) handleDeleteNotificationEndpoint(w http.Re
 		}
 		keys[k] = fld.Key
 	}
-	h.log.Debug("NotificationEndpoint deleted", zap.String("notificationEndpointID", fmt.Sprint(i)))
+	h.logger(r).Debug("NotificationEndpoint deleted", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpointID", fmt.Sprint(i)))
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// NotificationEndpointService is an http client for the influxdb.NotificationEndpointService server implementation.
-type NotificationEndpointService struct {
-	Client *httpc.Client
-	*UserResourceMappingService
-	*OrganizationService
-}
-
-// NewNotificationEndpointService constructs a new http NotificationEndpointService.
-func NewNotificationEndpointService(client *httpc.Client) *NotificationEndpointService {
-	return &NotificationEndpointService{
-		Client: client,
-		UserResourceMappingService: &UserResourceMappingService{
-			Client: client,
-		},
-		OrganizationService: &OrganizationService{
-			Client: client,
-		},
-	}
+// notificationEndpointDeletePlan lists everything deleting a notification endpoint removes.
+type notificationEndpointDeletePlan struct {
+	NotificationEndpointID influxdb.ID                     `json:"notificationEndpointID"`
+	LabelMappings          []influxdb.LabelMapping         `json:"labelMappings"`
+	UserResourceMappings   []*influxdb.UserResourceMapping `json:"userResourceMappings"`
+	SecretKeys             []string                        `json:"secretKeys"`
 }
 
-var _ influxdb.NotificationEndpointService = (*NotificationEndpointService)(nil)
-
-// FindNotificationEndpointByID returns a single notification endpoint by ID.
-func (s *NotificationEndpointService) FindNotificationEndpointByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
-	var resp notificationEndpointDecoder
-	err := s.Client.
-		Get(prefixNotificationEndpoints, id.String()).
-		DecodeJSON(&resp).
-		Do(ctx)
+// planNotificationEndpointDelete returns the cascading deletions of the notification
+// endpoint without performing them. It mirrors the delete: the label and user resource
+// mappings of the endpoint are removed along with its secrets, unless the context
+// carries the keep secrets hint.
+func (h *NotificationEndpointHandler) planNotificationEndpointDelete(ctx context.Context, id influxdb.ID) (*notificationEndpointDeletePlan, error) {
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	return resp.endpoint, nil
-}
+	if err := authorizeNotificationEndpoint(ctx, influxdb.WriteAction, edp); err != nil {
+		return nil, err
+	}
 
-// FindNotificationEndpoints returns a list of notification endpoints that match filter and the total count of matching notification endpoints.
-// Additional options provide pagination & sorting.
-func (s *NotificationEndpointService) FindNotificationEndpoints(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
-	params := findOptionParams(opt...)
-	if filter.ID != nil {
-		params = append(params, [2]string{"id", filter.ID.String()})
+	plan := &notificationEndpointDeletePlan{
+		NotificationEndpointID: id,
+		LabelMappings:          []influxdb.LabelMapping{},
+		SecretKeys:             []string{},
 	}
-	if filter.OrgID != nil {
-		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: id})
+	if err != nil {
+		return nil, err
 	}
-	if filter.Org != nil {
-		params = append(params, [2]string{"org", *filter.Org})
+	for _, l := range labels {
+		plan.LabelMappings = append(plan.LabelMappings, influxdb.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   id,
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		})
 	}
 
-	var resp struct {
-		Endpoints []notificationEndpointDecoder `json:"notificationEndpoints"`
-	}
-	err := s.Client.
-		Get(prefixNotificationEndpoints).
-		QueryParams(params...).
-		DecodeJSON(&resp).
-		Do(ctx)
+	plan.UserResourceMappings, _, err = h.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   id,
+		ResourceType: influxdb.NotificationEndpointResourceType,
+	})
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	var endpoints []influxdb.NotificationEndpoint
-	for _, e := range resp.Endpoints {
-		endpoints = append(endpoints, e.endpoint)
+	if !influxdb.NotificationEndpointSecretsKept(ctx) {
+		for _, fld := range edp.SecretFields() {
+			if fld.Key != "" {
+				plan.SecretKeys = append(plan.SecretKeys, fld.Key)
+			}
+		}
 	}
-	return endpoints, len(endpoints), nil
+	return plan, nil
 }
 
-// CreateNotificationEndpoint creates a new notification endpoint and sets b.ID with the new identifier.
-// TODO(@jsteenb2): this is unsatisfactory, we have no way of grabbing the new notification endpoint without
-//  serious hacky hackertoning. Put it on the list...
-func (s *NotificationEndpointService) CreateNotificationEndpoint(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
-	// userID is ignored here since server reads it off
-	// the token/auth. its a nothing burger here
-	var resp notificationEndpointDecoder
-	err := s.Client.
-		PostJSON(&notificationEndpointEncoder{ne: ne}, prefixNotificationEndpoints).
-		DecodeJSON(&resp).
-		Do(ctx)
+type rotateNotificationEndpointSecretRequest struct {
+	SecretKeySuffix string `json:"secretKeySuffix"`
+	NewValue        string `json:"newValue"`
+}
+
+func decodeRotateNotificationEndpointSecretRequest(ctx context.Context, r *http.Request) (influxdb.ID, rotateNotificationEndpointSecretRequest, error) {
+	id, err := decodeGetNotificationEndpointRequest(ctx)
 	if err != nil {
-		return err
+		return 0, rotateNotificationEndpointSecretRequest{}, err
 	}
-	// :sadpanda:
-	ne.SetID(resp.endpoint.GetID())
-	ne.SetOrgID(resp.endpoint.GetOrgID())
-	return nil
-}
+
+	var req rotateNotificationEndpointSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return 0, rotateNotificationEndpointSecretRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if req.SecretKeySuffix == "" {
+		return 0, rotateNotificationEndpointSecretRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "secretKeySuffix must be provided",
+		}
+	}
+	return id, req, nil
+}
+
+// handlePostNotificationEndpointRotateSecret is the HTTP handler for the POST /api/v2/notificationEndpoints/:id/rotateSecret route.
+// It replaces the value of a single secret without touching the endpoint record.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointRotateSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, req, err := decodeRotateNotificationEndpointSecretRequest(ctx, r)
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var fld influxdb.SecretField
+	for _, f := range edp.SecretFields() {
+		if f.Key != "" && strings.HasSuffix(f.Key, req.SecretKeySuffix) {
+			fld = f
+			break
+		}
+	}
+	if fld.Key == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("notification endpoint %s has no secret with suffix %q", id, req.SecretKeySuffix),
+		}, w)
+		return
+	}
+
+	if err := h.rotateSecret(ctx, edp.GetOrgID(), fld, req.NewValue); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.logger(r).Debug("NotificationEndpoint secret rotated", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpointID", id.String()), zap.String("secretKey", fld.Key))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rotateSecret stores the new value of the secret field in the secret store it asks
+// for. Only the endpoint secret service knows the named stores, the default secret
+// service is used without it.
+func (h *NotificationEndpointHandler) rotateSecret(ctx context.Context, orgID influxdb.ID, fld influxdb.SecretField, value string) error {
+	if h.EndpointSecretService == nil {
+		if fld.Store != "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("secret store %q is not available", fld.Store),
+			}
+		}
+		return h.SecretService.PatchSecrets(ctx, orgID, map[string]string{fld.Key: value})
+	}
+
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.SecretsResourceType, orgID)
+	if err != nil {
+		return err
+	}
+	if err := authorizer.IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+	fld.Value = &value
+	return h.EndpointSecretService.PatchNotificationEndpointSecrets(ctx, orgID, []influxdb.SecretField{fld})
+}
+
+type transferNotificationEndpointOwnershipRequest struct {
+	FromUserID influxdb.ID `json:"fromUserID"`
+	ToUserID   influxdb.ID `json:"toUserID"`
+}
+
+func decodeTransferNotificationEndpointOwnershipRequest(ctx context.Context, r *http.Request) (influxdb.ID, transferNotificationEndpointOwnershipRequest, error) {
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		return 0, transferNotificationEndpointOwnershipRequest{}, err
+	}
+
+	var req transferNotificationEndpointOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return 0, transferNotificationEndpointOwnershipRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if !req.FromUserID.Valid() || !req.ToUserID.Valid() {
+		return 0, transferNotificationEndpointOwnershipRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "fromUserID and toUserID are required",
+		}
+	}
+	return id, req, nil
+}
+
+// handlePostNotificationEndpointTransferOwnership is the HTTP handler for the
+// POST /api/v2/notificationEndpoints/:id/transferOwnership route. Only admins of the
+// endpoint's organization may hand an endpoint over to a new owner.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointTransferOwnership(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, req, err := decodeTransferNotificationEndpointOwnershipRequest(ctx, r)
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := authorizeOrg(ctx, influxdb.WriteAction, edp.GetOrgID()); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	err = h.ResourceOwnershipService.TransferResourceOwnership(ctx, influxdb.NotificationEndpointResourceType, id, req.FromUserID, req.ToUserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.logger(r).Debug("NotificationEndpoint ownership transferred",
+		zap.String("orgID", edp.GetOrgID().String()),
+		zap.String("notificationEndpointID", id.String()),
+		zap.String("fromUserID", req.FromUserID.String()),
+		zap.String("toUserID", req.ToUserID.String()),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type notificationEndpointVersionResponse struct {
+	Version              int             `json:"version"`
+	ReplacedAt           time.Time       `json:"replacedAt"`
+	NotificationEndpoint json.RawMessage `json:"notificationEndpoint"`
+}
+
+type notificationEndpointHistoryResponse struct {
+	Versions []notificationEndpointVersionResponse `json:"versions"`
+}
+
+// authorizeNotificationEndpoint checks that the caller may act on the notification endpoint.
+func authorizeNotificationEndpoint(ctx context.Context, action influxdb.Action, edp influxdb.NotificationEndpoint) error {
+	id, orgID := edp.GetID(), edp.GetOrgID()
+	p := influxdb.Permission{
+		Action: action,
+		Resource: influxdb.Resource{
+			Type:  influxdb.NotificationEndpointResourceType,
+			ID:    &id,
+			OrgID: &orgID,
+		},
+	}
+	return authorizer.IsAllowed(ctx, p)
+}
+
+// handleGetNotificationEndpointHistory is the HTTP handler for the GET /api/v2/notificationEndpoints/:id/history route.
+// It lists the kept prior configurations of the endpoint, oldest first.
+func (h *NotificationEndpointHandler) handleGetNotificationEndpointHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := authorizeNotificationEndpoint(ctx, influxdb.ReadAction, edp); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	versions, err := h.HistoryService.FindNotificationEndpointHistory(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	hide := h.hidesSecretReferences(ctx, edp.GetOrgID())
+	resp := notificationEndpointHistoryResponse{
+		Versions: make([]notificationEndpointVersionResponse, 0, len(versions)),
+	}
+	for _, v := range versions {
+		b, err := json.Marshal(v.NotificationEndpoint)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if hide {
+			if b, err = endpoint.MaskSecretReferences(v.NotificationEndpoint, b); err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+		}
+		resp.Versions = append(resp.Versions, notificationEndpointVersionResponse{
+			Version:              v.Version,
+			ReplacedAt:           v.ReplacedAt,
+			NotificationEndpoint: b,
+		})
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+// handlePostNotificationEndpointRestore is the HTTP handler for the
+// POST /api/v2/notificationEndpoints/:id/history/:version/restore route. It updates the
+// endpoint back to a kept prior configuration.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointRestore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	version, err := strconv.Atoi(httprouter.ParamsFromContext(ctx).ByName("version"))
+	if err != nil || version < 1 {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "version must be a positive integer",
+		}, w)
+		return
+	}
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := authorizeNotificationEndpoint(ctx, influxdb.WriteAction, edp); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	edp, err = h.HistoryService.RestoreNotificationEndpointVersion(ctx, id, version, auth.GetUserID())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.logger(r).Debug("NotificationEndpoint restored",
+		zap.String("orgID", edp.GetOrgID().String()),
+		zap.String("notificationEndpointID", id.String()),
+		zap.Int("version", version),
+	)
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	resp := h.newNotificationEndpointResponse(ctx, edp, labels)
+	resp.Warnings = endpoint.Warnings(edp)
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+// handleGetNotificationEndpointExport is the HTTP handler for the GET /api/v2/notificationEndpoints/:id/export route.
+// The format query param selects the representation, json by default or hcl for a terraform resource block.
+func (h *NotificationEndpointHandler) handleGetNotificationEndpointExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "hcl" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported export format %q", format),
+		}, w)
+		return
+	}
+
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.logger(r).Debug("NotificationEndpoint exported", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpointID", id.String()), zap.String("format", format))
+
+	if format != "hcl" {
+		b, err := json.Marshal(edp)
+		if err == nil && h.hidesSecretReferences(ctx, edp.GetOrgID()) {
+			b, err = endpoint.MaskSecretReferences(edp, b)
+		}
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if err := encodeResponse(ctx, w, http.StatusOK, json.RawMessage(b)); err != nil {
+			logEncodingError(h.logger(r), r, err)
+		}
+		return
+	}
+
+	b, err := endpoint.MarshalHCL(edp)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		logEncodingError(h.logger(r), r, err)
+	}
+}
+
+type muteNotificationEndpointRequest struct {
+	Until    *time.Time `json:"until,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+}
+
+func decodeMuteNotificationEndpointRequest(ctx context.Context, r *http.Request, now time.Time) (influxdb.ID, time.Time, error) {
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var req muteNotificationEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return 0, time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	if (req.Until == nil) == (req.Duration == "") {
+		return 0, time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "exactly one of until or duration must be provided",
+		}
+	}
+
+	until := now
+	if req.Until != nil {
+		until = *req.Until
+	} else {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return 0, time.Time{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid duration",
+				Err:  err,
+			}
+		}
+		until = now.Add(d)
+	}
+	if !until.After(now) {
+		return 0, time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "notification endpoint can only be muted until a time in the future",
+		}
+	}
+	return id, until.UTC(), nil
+}
+
+// handlePostNotificationEndpointMute is the HTTP handler for the POST /api/v2/notificationEndpoints/:id/mute route.
+// The endpoint stays muted until the given time, after which it is unmuted automatically.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointMute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, until, err := decodeMuteNotificationEndpointRequest(ctx, r, time.Now())
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	h.patchNotificationEndpointMute(w, r, id, influxdb.NotificationEndpointUpdate{MutedUntil: &until})
+}
+
+// handlePostNotificationEndpointUnmute is the HTTP handler for the POST /api/v2/notificationEndpoints/:id/unmute route.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointUnmute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	h.patchNotificationEndpointMute(w, r, id, influxdb.NotificationEndpointUpdate{Unmute: true})
+}
+
+func (h *NotificationEndpointHandler) patchNotificationEndpointMute(w http.ResponseWriter, r *http.Request, id influxdb.ID, upd influxdb.NotificationEndpointUpdate) {
+	ctx := r.Context()
+	edp, err := h.NotificationEndpointService.PatchNotificationEndpoint(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.logger(r).Debug("NotificationEndpoint mute updated", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpoint", fmt.Sprint(edp)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, h.newNotificationEndpointResponse(ctx, edp, labels)); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type previewNotificationEndpointRequest struct {
+	Alert           map[string]interface{} `json:"alert"`
+	MessageTemplate string                 `json:"messageTemplate"`
+}
+
+func decodePreviewNotificationEndpointRequest(ctx context.Context, r *http.Request) (influxdb.ID, previewNotificationEndpointRequest, error) {
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		return 0, previewNotificationEndpointRequest{}, err
+	}
+
+	var req previewNotificationEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return 0, previewNotificationEndpointRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if len(req.Alert) == 0 {
+		return 0, previewNotificationEndpointRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "a sample alert must be provided",
+		}
+	}
+	return id, req, nil
+}
+
+// handlePostNotificationEndpointPreview is the HTTP handler for the POST /api/v2/notificationEndpoints/:id/preview route.
+// It renders the request the endpoint would send for the sample alert, without sending anything.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, req, err := decodePreviewNotificationEndpointRequest(ctx, r)
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	preview, err := endpoint.PreviewPayload(edp, req.Alert, req.MessageTemplate)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.logger(r).Debug("NotificationEndpoint previewed", zap.String("orgID", edp.GetOrgID().String()), zap.String("notificationEndpointID", id.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, preview); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type cloneNotificationEndpointsToOrgRequest struct {
+	SourceOrgID influxdb.ID `json:"sourceOrgID"`
+	TargetOrgID influxdb.ID `json:"targetOrgID"`
+}
+
+func decodeCloneNotificationEndpointsToOrgRequest(r *http.Request) (cloneNotificationEndpointsToOrgRequest, error) {
+	var req cloneNotificationEndpointsToOrgRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return cloneNotificationEndpointsToOrgRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if !req.SourceOrgID.Valid() || !req.TargetOrgID.Valid() {
+		return cloneNotificationEndpointsToOrgRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "sourceOrgID and targetOrgID must be provided",
+		}
+	}
+	if req.SourceOrgID == req.TargetOrgID {
+		return cloneNotificationEndpointsToOrgRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "sourceOrgID and targetOrgID must be different",
+		}
+	}
+	return req, nil
+}
+
+type cloneNotificationEndpointsToOrgResponse struct {
+	NotificationEndpoints []notificationEndpointResponse `json:"notificationEndpoints"`
+}
+
+// handleCloneNotificationEndpointsToOrg is the HTTP handler for the POST /api/v2/notificationEndpoints/cloneToOrg route.
+// Every endpoint of the source org is copied into the target org with new IDs, empty
+// placeholder secrets and labels matched by name.
+func (h *NotificationEndpointHandler) handleCloneNotificationEndpointsToOrg(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeCloneNotificationEndpointsToOrgRequest(r)
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	for _, orgID := range []influxdb.ID{req.SourceOrgID, req.TargetOrgID} {
+		id := orgID
+		p := influxdb.Permission{
+			Action:   influxdb.WriteAction,
+			Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &id},
+		}
+		if err := authorizer.IsAllowed(ctx, p); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	edps, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &req.SourceOrgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	resp := cloneNotificationEndpointsToOrgResponse{
+		NotificationEndpoints: make([]notificationEndpointResponse, 0, len(edps)),
+	}
+	for _, edp := range edps {
+		cloned, err := endpoint.CopyWithPlaceholderSecrets(edp)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		cloned.SetOrgID(req.TargetOrgID)
+
+		if err := h.NotificationEndpointService.CreateNotificationEndpoint(ctx, cloned, auth.GetUserID()); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+
+		labels, err := h.cloneNotificationEndpointLabels(ctx, edp.GetID(), cloned.GetID(), req.TargetOrgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		resp.NotificationEndpoints = append(resp.NotificationEndpoints, h.newNotificationEndpointResponse(ctx, cloned, labels))
+	}
+	h.logger(r).Debug("NotificationEndpoints cloned",
+		zap.String("orgID", req.TargetOrgID.String()),
+		zap.String("sourceOrgID", req.SourceOrgID.String()),
+		zap.String("targetOrgID", req.TargetOrgID.String()),
+		zap.Int("count", len(resp.NotificationEndpoints)),
+	)
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+// import strategies for endpoints whose name is already taken in the org.
+const (
+	importStrategySkip      = "skip"
+	importStrategyOverwrite = "overwrite"
+	importStrategyRename    = "rename"
+)
+
+type notificationEndpointsBundleRequest struct {
+	OrgID                 influxdb.ID
+	NotificationEndpoints []influxdb.NotificationEndpoint
+	// Strategy resolves name collisions on import, by default they fail the import.
+	Strategy string
+
+	// raws are the endpoints as sent, so they can be checked without touching the decoded ones.
+	raws []json.RawMessage
+}
+
+func decodeNotificationEndpointsBundleRequest(r *http.Request) (notificationEndpointsBundleRequest, error) {
+	var orgID influxdb.ID
+	if err := orgID.DecodeFromString(r.URL.Query().Get("orgID")); err != nil {
+		return notificationEndpointsBundleRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID must be provided",
+			Err:  err,
+		}
+	}
+
+	var bundle struct {
+		NotificationEndpoints []json.RawMessage `json:"notificationEndpoints"`
+		Strategy              string            `json:"strategy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		return notificationEndpointsBundleRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	switch bundle.Strategy {
+	case "", importStrategySkip, importStrategyOverwrite, importStrategyRename:
+	default:
+		return notificationEndpointsBundleRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported import strategy %q, must be one of skip, overwrite or rename", bundle.Strategy),
+		}
+	}
+
+	req := notificationEndpointsBundleRequest{
+		OrgID:                 orgID,
+		NotificationEndpoints: make([]influxdb.NotificationEndpoint, 0, len(bundle.NotificationEndpoints)),
+		Strategy:              bundle.Strategy,
+		raws:                  bundle.NotificationEndpoints,
+	}
+	names := make(map[string]bool, len(bundle.NotificationEndpoints))
+	for _, b := range bundle.NotificationEndpoints {
+		edp, err := decodeNotificationEndpoint(b)
+		if err != nil {
+			return notificationEndpointsBundleRequest{}, err
+		}
+		edp.Normalize()
+		if names[edp.GetName()] {
+			return notificationEndpointsBundleRequest{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("notification endpoint %q is declared more than once", edp.GetName()),
+			}
+		}
+		names[edp.GetName()] = true
+		edp.SetOrgID(orgID)
+		req.NotificationEndpoints = append(req.NotificationEndpoints, edp)
+	}
+	return req, nil
+}
+
+type notificationEndpointsDiffResponse struct {
+	Creates []influxdb.NotificationEndpoint `json:"creates"`
+	Updates []influxdb.NotificationEndpoint `json:"updates"`
+	// Deletes are the stored endpoints, encoded so their secret key references can be
+	// masked like in any other response.
+	Deletes []json.RawMessage `json:"deletes"`
+}
+
+// handlePostNotificationEndpointsDiff is the HTTP handler for the POST /api/v2/notificationEndpoints/diff route.
+// It computes the creates, updates and deletes needed to reconcile the org's endpoints with the
+// desired state, matching endpoints by name, without applying any of them. Secret values of the
+// desired state are never echoed back.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointsDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeNotificationEndpointsBundleRequest(r)
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	current, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &req.OrgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	byName := make(map[string]influxdb.NotificationEndpoint, len(current))
+	for _, edp := range current {
+		byName[edp.GetName()] = edp
+	}
+
+	resp := notificationEndpointsDiffResponse{
+		Creates: []influxdb.NotificationEndpoint{},
+		Updates: []influxdb.NotificationEndpoint{},
+		Deletes: []json.RawMessage{},
+	}
+	for _, desired := range req.NotificationEndpoints {
+		existing, ok := byName[desired.GetName()]
+		delete(byName, desired.GetName())
+		if ok {
+			eq, err := endpoint.Equal(existing, desired)
+			if err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+			if eq {
+				continue
+			}
+		}
+
+		planned, err := endpoint.CopyWithPlaceholderSecrets(desired)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if !ok {
+			resp.Creates = append(resp.Creates, planned)
+			continue
+		}
+		planned.SetID(existing.GetID())
+		resp.Updates = append(resp.Updates, planned)
+	}
+	hideSecretReferences := h.hidesSecretReferences(ctx, req.OrgID)
+	for _, edp := range current {
+		if _, ok := byName[edp.GetName()]; !ok {
+			continue
+		}
+		b, err := json.Marshal(edp)
+		if err == nil && hideSecretReferences {
+			b, err = endpoint.MaskSecretReferences(edp, b)
+		}
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		resp.Deletes = append(resp.Deletes, b)
+	}
+	h.logger(r).Debug("NotificationEndpoints diffed",
+		zap.String("orgID", req.OrgID.String()),
+		zap.Int("creates", len(resp.Creates)),
+		zap.Int("updates", len(resp.Updates)),
+		zap.Int("deletes", len(resp.Deletes)),
+	)
+
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+type notificationEndpointsBundle struct {
+	NotificationEndpoints []influxdb.NotificationEndpoint   `json:"notificationEndpoints"`
+	NotificationRules     []notificationEndpointsBundleRule `json:"notificationRules,omitempty"`
+}
+
+// notificationEndpointsBundleRule is a notification rule of an export bundle. The exported
+// endpoints have no IDs, so the rule names the endpoint it sends to.
+type notificationEndpointsBundleRule struct {
+	EndpointName string                    `json:"endpointName"`
+	Rule         influxdb.NotificationRule `json:"rule"`
+}
+
+// handleGetNotificationEndpointsExport is the HTTP handler for the GET /api/v2/notificationEndpoints/export route.
+// It returns every endpoint of the org as a bundle that can be imported or diffed, without IDs
+// and with empty placeholder secrets. With format=targz the endpoints are streamed as a tar.gz
+// archive holding one <type>-<name>.json file per endpoint instead. With withRules=true the
+// notification rules sending to the endpoints are added to the bundle, making it a
+// self-contained alerting config.
+func (h *NotificationEndpointHandler) handleGetNotificationEndpointsExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var orgID influxdb.ID
+	if err := orgID.DecodeFromString(r.URL.Query().Get("orgID")); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID must be provided",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "targz" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported export format %q", format),
+		}, w)
+		return
+	}
+
+	var withRules bool
+	if v := r.URL.Query().Get("withRules"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "withRules must be a boolean",
+				Err:  err,
+			}, w)
+			return
+		}
+		withRules = b
+	}
+	if withRules && format == "targz" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "withRules is only supported by the json export format",
+		}, w)
+		return
+	}
+
+	edps, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &orgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	bundle := notificationEndpointsBundle{
+		NotificationEndpoints: make([]influxdb.NotificationEndpoint, 0, len(edps)),
+	}
+	for _, edp := range edps {
+		exported, err := endpoint.CopyWithPlaceholderSecrets(edp)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		bundle.NotificationEndpoints = append(bundle.NotificationEndpoints, exported)
+	}
+	if withRules {
+		rules, err := h.exportNotificationRules(ctx, orgID, edps)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		bundle.NotificationRules = rules
+	}
+	h.logger(r).Debug("NotificationEndpoints exported",
+		zap.String("orgID", orgID.String()),
+		zap.Int("count", len(bundle.NotificationEndpoints)),
+		zap.Int("rules", len(bundle.NotificationRules)),
+		zap.String("format", format),
+	)
+
+	if format == "targz" {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "notification-endpoints-"+orgID.String()+".tar.gz"))
+		w.WriteHeader(http.StatusOK)
+		if err := writeNotificationEndpointsTarGz(w, bundle.NotificationEndpoints); err != nil {
+			logEncodingError(h.logger(r), r, err)
+		}
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, bundle); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+// exportNotificationRules returns the notification rules of the org that send to one of
+// the endpoints, without their tasks.
+func (h *NotificationEndpointHandler) exportNotificationRules(ctx context.Context, orgID influxdb.ID, edps []influxdb.NotificationEndpoint) ([]notificationEndpointsBundleRule, error) {
+	names := make(map[influxdb.ID]string, len(edps))
+	for _, edp := range edps {
+		names[edp.GetID()] = edp.GetName()
+	}
+
+	nrs, _, err := h.NotificationRuleStore.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{
+		OrgID: &orgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationRuleResourceType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]notificationEndpointsBundleRule, 0, len(nrs))
+	for _, nr := range nrs {
+		name, ok := names[nr.GetEndpointID()]
+		if !ok {
+			continue
+		}
+		nr.ClearPrivateData()
+		rules = append(rules, notificationEndpointsBundleRule{
+			EndpointName: name,
+			Rule:         nr,
+		})
+	}
+	return rules, nil
+}
+
+// writeNotificationEndpointsTarGz writes the endpoints as a tar.gz archive with one
+// indented <type>-<name>.json file per endpoint.
+func writeNotificationEndpointsTarGz(w io.Writer, edps []influxdb.NotificationEndpoint) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, edp := range edps {
+		b, err := json.MarshalIndent(edp, "", "  ")
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:    notificationEndpointExportFileName(edp),
+			Mode:    0644,
+			Size:    int64(len(b)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(b); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// notificationEndpointExportFileName names the archive file of an endpoint, keeping the
+// endpoint name from escaping the archive root.
+func notificationEndpointExportFileName(edp influxdb.NotificationEndpoint) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(edp.GetName())
+	return edp.Type() + "-" + name + ".json"
+}
+
+type importNotificationEndpointsResponse struct {
+	NotificationEndpoints []notificationEndpointResponse `json:"notificationEndpoints"`
+	// Skipped lists the names of the endpoints left untouched by the skip strategy.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// handlePostNotificationEndpointsImport is the HTTP handler for the POST /api/v2/notificationEndpoints/import route.
+// Every endpoint of the bundle is created in the org with a new ID. Secret values provided
+// in the bundle are stored, secrets left empty must be filled in afterwards.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointsImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if v := r.URL.Query().Get("validateOnly"); v != "" {
+		validateOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "validateOnly must be a boolean",
+				Err:  err,
+			}, w)
+			return
+		}
+		if validateOnly {
+			h.validateNotificationEndpointsImport(w, r)
+			return
+		}
+	}
+
+	req, err := decodeNotificationEndpointsBundleRequest(r)
+	if err != nil {
+		h.logger(r).Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// check every endpoint before any is written, so a rejected bundle imports nothing.
+	problems, err := h.notificationEndpointsImportProblems(ctx, req.OrgID, req.Strategy, req.raws)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if len(problems) > 0 {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("notification endpoint at index %d can't be imported: %s", problems[0].Index, problems[0].Message),
+		}, w)
+		return
+	}
+	for _, edp := range req.NotificationEndpoints {
+		if err := h.checkPublicAddresses(ctx, edp); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	existing := make(map[string]influxdb.NotificationEndpoint)
+	if req.Strategy != "" {
+		edps, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &req.OrgID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		for _, edp := range edps {
+			existing[edp.GetName()] = edp
+		}
+	}
+
+	resp := importNotificationEndpointsResponse{
+		NotificationEndpoints: make([]notificationEndpointResponse, 0, len(req.NotificationEndpoints)),
+	}
+	for _, edp := range req.NotificationEndpoints {
+		current, collides := existing[edp.GetName()]
+		switch {
+		case collides && req.Strategy == importStrategySkip:
+			resp.Skipped = append(resp.Skipped, edp.GetName())
+			continue
+		case collides && req.Strategy == importStrategyOverwrite:
+			edp.SetID(current.GetID())
+			endpoint.InheritSecrets(edp, current)
+			updated, err := h.NotificationEndpointService.UpdateNotificationEndpoint(ctx, current.GetID(), edp, auth.GetUserID())
+			if err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+			resp.NotificationEndpoints = append(resp.NotificationEndpoints, h.newNotificationEndpointResponse(ctx, updated, nil))
+			continue
+		case collides && req.Strategy == importStrategyRename:
+			edp.SetName(importedNotificationEndpointName(edp.GetName(), existing))
+		}
+
+		if err := h.NotificationEndpointService.CreateNotificationEndpoint(ctx, edp, auth.GetUserID()); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		existing[edp.GetName()] = edp
+		resp.NotificationEndpoints = append(resp.NotificationEndpoints, h.newNotificationEndpointResponse(ctx, edp, nil))
+	}
+	h.logger(r).Debug("NotificationEndpoints imported",
+		zap.String("orgID", req.OrgID.String()),
+		zap.String("strategy", req.Strategy),
+		zap.Int("count", len(resp.NotificationEndpoints)),
+		zap.Int("skipped", len(resp.Skipped)),
+	)
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+// notificationEndpointImportProblem is a reason an endpoint of a bundle can't be imported.
+type notificationEndpointImportProblem struct {
+	// Index is the position of the endpoint in the bundle.
+	Index   int    `json:"index"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+type validateNotificationEndpointsImportResponse struct {
+	Problems []notificationEndpointImportProblem `json:"problems"`
+}
+
+// validateNotificationEndpointsImport serves the import route with validateOnly=true. It reports
+// every problem of the bundle that would fail the import, rather than stopping at the first one,
+// without creating or updating anything. The response is a 200 when the bundle can be imported
+// and a 400 otherwise.
+func (h *NotificationEndpointHandler) validateNotificationEndpointsImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var orgID influxdb.ID
+	if err := orgID.DecodeFromString(r.URL.Query().Get("orgID")); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID must be provided",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	var bundle struct {
+		NotificationEndpoints []json.RawMessage `json:"notificationEndpoints"`
+		Strategy              string            `json:"strategy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}, w)
+		return
+	}
+	switch bundle.Strategy {
+	case "", importStrategySkip, importStrategyOverwrite, importStrategyRename:
+	default:
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported import strategy %q, must be one of skip, overwrite or rename", bundle.Strategy),
+		}, w)
+		return
+	}
+
+	problems, err := h.notificationEndpointsImportProblems(ctx, orgID, bundle.Strategy, bundle.NotificationEndpoints)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	resp := validateNotificationEndpointsImportResponse{
+		Problems: problems,
+	}
+	h.logger(r).Debug("NotificationEndpoints import validated",
+		zap.String("orgID", orgID.String()),
+		zap.Int("count", len(bundle.NotificationEndpoints)),
+		zap.Int("problems", len(resp.Problems)),
+	)
+
+	status := http.StatusOK
+	if len(resp.Problems) > 0 {
+		status = http.StatusBadRequest
+	}
+	if err := encodeResponse(ctx, w, status, resp); err != nil {
+		logEncodingError(h.logger(r), r, err)
+		return
+	}
+}
+
+// notificationEndpointsImportProblems reports every problem of the bundle that would fail
+// importing it into the org. The endpoints are decoded from raws afresh, as they are
+// changed to be checked the way they would be created.
+func (h *NotificationEndpointHandler) notificationEndpointsImportProblems(ctx context.Context, orgID influxdb.ID, strategy string, raws []json.RawMessage) ([]notificationEndpointImportProblem, error) {
+	// without a strategy, a name taken in the org fails the import.
+	existing := make(map[string]bool)
+	if strategy == "" {
+		edps, _, err := h.NotificationEndpointService.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &orgID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, edp := range edps {
+			existing[edp.GetName()] = true
+		}
+	}
+
+	problems := []notificationEndpointImportProblem{}
+	names := make(map[string]bool, len(raws))
+	for i, b := range raws {
+		edp, err := decodeNotificationEndpoint(b)
+		if err != nil {
+			problems = append(problems, notificationEndpointImportProblem{
+				Index:   i,
+				Message: influxdb.ErrorMessage(err),
+			})
+			continue
+		}
+		edp.Normalize()
+		problem := func(msg string) {
+			problems = append(problems, notificationEndpointImportProblem{
+				Index:   i,
+				Name:    edp.GetName(),
+				Message: msg,
+			})
+		}
+
+		switch {
+		case names[edp.GetName()]:
+			problem(fmt.Sprintf("notification endpoint %q is declared more than once", edp.GetName()))
+		case existing[edp.GetName()]:
+			problem(fmt.Sprintf("notification endpoint %q already exists in the organization", edp.GetName()))
+		}
+		names[edp.GetName()] = true
+
+		if h.EndpointSecretService != nil {
+			if err := h.EndpointSecretService.ValidSecretStores(endpoint.SetSecretFields(edp)); err != nil {
+				problem(influxdb.ErrorMessage(err))
+			}
+		}
+
+		// the endpoint is checked as it would be created, with an ID assigned and
+		// the keys of its secret values backfilled.
+		edp.SetOrgID(orgID)
+		edp.SetID(influxdb.ID(1))
+		edp.BackfillSecretKeys()
+		if err := edp.Valid(); err != nil {
+			problem(influxdb.ErrorMessage(err))
+		}
+	}
+	return problems, nil
+}
+
+// importedNotificationEndpointName returns the name with the first " (imported N)"
+// suffix that is not taken yet.
+func importedNotificationEndpointName(name string, taken map[string]influxdb.NotificationEndpoint) string {
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (imported %d)", name, n)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// cloneNotificationEndpointLabels maps the labels of the source endpoint onto the cloned endpoint,
+// resolving each label by name in the target org and creating it when it does not exist.
+func (h *NotificationEndpointHandler) cloneNotificationEndpointLabels(ctx context.Context, srcID, dstID, orgID influxdb.ID) ([]*influxdb.Label, error) {
+	srcLabels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: srcID})
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []*influxdb.Label
+	for _, src := range srcLabels {
+		existing, err := h.LabelService.FindLabels(ctx, influxdb.LabelFilter{Name: src.Name, OrgID: &orgID})
+		if err != nil {
+			return nil, err
+		}
+
+		var label *influxdb.Label
+		if len(existing) > 0 {
+			label = existing[0]
+		} else {
+			label = &influxdb.Label{
+				OrgID:      orgID,
+				Name:       src.Name,
+				Properties: src.Properties,
+			}
+			if err := h.LabelService.CreateLabel(ctx, label); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := h.LabelService.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      label.ID,
+			ResourceID:   dstID,
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		}); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// NotificationEndpointService is an http client for the influxdb.NotificationEndpointService server implementation.
+type NotificationEndpointService struct {
+	Client *httpc.Client
+	*UserResourceMappingService
+	*OrganizationService
+
+	retries int
+	backoff time.Duration
+
+	userAgent string
+}
+
+// NotificationEndpointServiceOptFn is a functional option for the http NotificationEndpointService.
+type NotificationEndpointServiceOptFn func(*NotificationEndpointService)
+
+// WithRetry retries idempotent calls up to n times when the server responds with
+// a 5xx or can't be reached. The wait between attempts starts at backoff and doubles
+// after every attempt.
+func WithRetry(n int, backoff time.Duration) NotificationEndpointServiceOptFn {
+	return func(s *NotificationEndpointService) {
+		s.retries = n
+		s.backoff = backoff
+	}
+}
+
+// WithUserAgent sends the user agent with every request of the service, so that proxies
+// and audit logs can tell its callers apart.
+func WithUserAgent(userAgent string) NotificationEndpointServiceOptFn {
+	return func(s *NotificationEndpointService) {
+		s.userAgent = userAgent
+	}
+}
+
+// NewNotificationEndpointService constructs a new http NotificationEndpointService.
+func NewNotificationEndpointService(client *httpc.Client, opts ...NotificationEndpointServiceOptFn) *NotificationEndpointService {
+	s := &NotificationEndpointService{}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.userAgent != "" {
+		// cloning only fails on invalid client options, which a header never is.
+		if c, err := client.Clone(httpc.WithHeader("User-Agent", s.userAgent)); err == nil {
+			client = c
+		}
+	}
+	s.Client = client
+	s.UserResourceMappingService = &UserResourceMappingService{
+		Client: client,
+	}
+	s.OrganizationService = &OrganizationService{
+		Client: client,
+	}
+	return s
+}
+
+var (
+	_ influxdb.NotificationEndpointService        = (*NotificationEndpointService)(nil)
+	_ influxdb.NotificationEndpointHealthService  = (*NotificationEndpointService)(nil)
+	_ influxdb.NotificationEndpointHistoryService = (*NotificationEndpointService)(nil)
+)
+
+// doIdempotent calls the request built by newReq, retrying it per the retry policy.
+// The request is rebuilt for every attempt, as its body can only be read once.
+func (s *NotificationEndpointService) doIdempotent(ctx context.Context, newReq func() *httpc.Req) error {
+	if s.retries <= 0 {
+		return newReq().Do(ctx)
+	}
+
+	var status int
+	do := func() error {
+		status = 0
+		return newReq().
+			RespFn(func(resp *http.Response) error {
+				status = resp.StatusCode
+				return nil
+			}).
+			Do(ctx)
+	}
+
+	err := do()
+	backoff := s.backoff
+	for i := 0; i < s.retries && err != nil && (status == 0 || status >= 500); i++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = do()
+	}
+	return err
+}
+
+// FindNotificationEndpointByID returns a single notification endpoint by ID.
+func (s *NotificationEndpointService) FindNotificationEndpointByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	var resp notificationEndpointDecoder
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.
+			Get(prefixNotificationEndpoints, id.String()).
+			DecodeJSON(&resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.endpoint, nil
+}
+
+// FindNotificationEndpoints returns a list of notification endpoints that match filter and the total count of matching notification endpoints.
+// Additional options provide pagination & sorting.
+func (s *NotificationEndpointService) FindNotificationEndpoints(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+	params := findOptionParams(opt...)
+	if filter.ID != nil {
+		params = append(params, [2]string{"id", filter.ID.String()})
+	}
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Org != nil {
+		params = append(params, [2]string{"org", *filter.Org})
+	}
+	if filter.GroupID != nil {
+		params = append(params, [2]string{"group", filter.GroupID.String()})
+	}
+	if filter.ExcludeLabel != nil {
+		params = append(params, [2]string{"excludeLabel", *filter.ExcludeLabel})
+	}
+
+	var resp struct {
+		Endpoints []notificationEndpointDecoder `json:"notificationEndpoints"`
+	}
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.
+			Get(prefixNotificationEndpoints).
+			QueryParams(params...).
+			DecodeJSON(&resp)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var endpoints []influxdb.NotificationEndpoint
+	for _, e := range resp.Endpoints {
+		endpoints = append(endpoints, e.endpoint)
+	}
+	return endpoints, len(endpoints), nil
+}
+
+// notificationEndpointStreamPageSize is the number of endpoints FindStream fetches per request.
+const notificationEndpointStreamPageSize = 100
+
+// NotificationEndpointIterator yields the notification endpoints matching a filter one at a time,
+// fetching them a page at a time so memory stays bounded.
+type NotificationEndpointIterator struct {
+	ctx    context.Context
+	s      *NotificationEndpointService
+	filter influxdb.NotificationEndpointFilter
+	opts   influxdb.FindOptions
+
+	page []influxdb.NotificationEndpoint
+	cur  influxdb.NotificationEndpoint
+	last bool
+	err  error
+}
+
+// FindStream returns an iterator over the notification endpoints that match filter. The
+// first page is fetched eagerly, so a failing request is reported here.
+func (s *NotificationEndpointService) FindStream(ctx context.Context, filter influxdb.NotificationEndpointFilter) (*NotificationEndpointIterator, error) {
+	it := &NotificationEndpointIterator{
+		ctx:    ctx,
+		s:      s,
+		filter: filter,
+		opts:   influxdb.FindOptions{Limit: notificationEndpointStreamPageSize},
+	}
+	if err := it.fetch(); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// Next advances the iterator to the next endpoint. It returns false when the endpoints
+// are exhausted or a page failed to load, check Err to tell them apart.
+func (it *NotificationEndpointIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.last {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Endpoint returns the endpoint the iterator is at.
+func (it *NotificationEndpointIterator) Endpoint() influxdb.NotificationEndpoint {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *NotificationEndpointIterator) Err() error {
+	return it.err
+}
+
+func (it *NotificationEndpointIterator) fetch() error {
+	page, _, err := it.s.FindNotificationEndpoints(it.ctx, it.filter, it.opts)
+	if err != nil {
+		return err
+	}
+	it.page = page
+	it.opts.Offset += len(page)
+	// a short page is the last one.
+	it.last = len(page) < it.opts.Limit
+	return nil
+}
+
+// CreateNotificationEndpoint creates a new notification endpoint and sets b.ID with the new identifier.
+// Use Create to get the endpoint as stored by the server.
+// Creating is not idempotent, so it is never retried.
+func (s *NotificationEndpointService) CreateNotificationEndpoint(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
+	created, err := s.Create(ctx, ne, userID)
+	if err != nil {
+		return err
+	}
+	ne.SetID(created.GetID())
+	ne.SetOrgID(created.GetOrgID())
+	return nil
+}
+
+// Create creates a new notification endpoint and returns it as stored by the server, with
+// its new identifier and CRUD log. Unlike CreateNotificationEndpoint, ne is left untouched.
+// Creating is not idempotent, so it is never retried.
+func (s *NotificationEndpointService) Create(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	// userID is ignored here since server reads it off
+	// the token/auth. its a nothing burger here
+	var resp notificationEndpointDecoder
+	err := s.Client.
+		PostJSON(&notificationEndpointEncoder{ne: ne}, prefixNotificationEndpoints).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.endpoint, nil
+}
 
 // UpdateNotificationEndpoint updates a single notification endpoint.
 // Returns the new notification endpoint after update.
 func (s *NotificationEndpointService) UpdateNotificationEndpoint(ctx context.Context, id influxdb.ID, ne influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
 	// userID is ignored since userID is grabbed off the http auth set on the client
 	var resp notificationEndpointDecoder
-	err := s.Client.
-		PutJSON(&notificationEndpointEncoder{ne: ne}, prefixNotificationEndpoints, id.String()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.
+			PutJSON(&notificationEndpointEncoder{ne: ne}, prefixNotificationEndpoints, id.String()).
+			DecodeJSON(&resp)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -676,9 +3211,9 @@ func (s *NotificationEndpointService) PatchNotificationEndpoint(ctx context.Cont
 //  then see what falls out :flushed... for now returning nothing for secrets, orgID, and only returning an error. This makes
 //  the code/design smell super obvious imo
 func (s *NotificationEndpointService) DeleteNotificationEndpoint(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
-	err := s.Client.
-		Delete(prefixNotificationEndpoints, id.String()).
-		Do(ctx)
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.Delete(prefixNotificationEndpoints, id.String())
+	})
 	return nil, 0, err
 }
 
@@ -700,21 +3235,155 @@ func (n *notificationEndpointEncoder) MarshalJSON() ([]byte, error) {
 
 	// this makes me queezy and altogether sad
 	fieldMap := map[string]string{
+		"-ca-cert":     "caCert",
 		"-password":    "password",
 		"-routing-key": "routingKey",
 		"-token":       "token",
 		"-username":    "username",
 	}
+	// the keys of endpoints with an ID are prefixed with it, so fields are matched by
+	// suffix. Secrets without a value keep their key reference.
 	for _, sec := range n.ne.SecretFields() {
-		var v string
-		if sec.Value != nil {
-			v = *sec.Value
+		if sec.Value == nil {
+			continue
+		}
+		for suffix, name := range fieldMap {
+			if strings.HasSuffix(sec.Key, suffix) {
+				ughhh[name] = *sec.Value
+			}
 		}
-		ughhh[fieldMap[sec.Key]] = v
 	}
 	return json.Marshal(ughhh)
 }
 
+// Ping checks that the notification endpoint service is reachable and accepts the client's
+// credentials by listing a single endpoint. It is not retried, so an unhealthy service
+// is reported right away.
+func (s *NotificationEndpointService) Ping(ctx context.Context) error {
+	err := s.Client.
+		Get(prefixNotificationEndpoints).
+		QueryParams([2]string{"limit", "1"}).
+		Do(ctx)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.ErrorCode(err),
+			Msg:  "notification endpoint service is unavailable",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+// Export returns every notification endpoint of the org as a bundle, without IDs and
+// with empty placeholder secrets. The bundle can be restored with Import.
+func (s *NotificationEndpointService) Export(ctx context.Context, orgID influxdb.ID) ([]byte, error) {
+	var bundle []byte
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.
+			Get(notificationEndpointsExportPath).
+			QueryParams([2]string{"orgID", orgID.String()}).
+			Decode(func(resp *http.Response) error {
+				b, err := ioutil.ReadAll(resp.Body)
+				bundle = b
+				return err
+			})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Import creates every notification endpoint of the bundle in the org and returns them.
+// Importing is not idempotent, so it is never retried.
+func (s *NotificationEndpointService) Import(ctx context.Context, orgID influxdb.ID, bundle []byte) ([]influxdb.NotificationEndpoint, error) {
+	var resp struct {
+		Endpoints []notificationEndpointDecoder `json:"notificationEndpoints"`
+	}
+	err := s.Client.
+		Post(bodyRawJSON(bundle), notificationEndpointsImportPath).
+		QueryParams([2]string{"orgID", orgID.String()}).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	edps := make([]influxdb.NotificationEndpoint, 0, len(resp.Endpoints))
+	for _, e := range resp.Endpoints {
+		edps = append(edps, e.endpoint)
+	}
+	return edps, nil
+}
+
+// CheckAll runs a connectivity check of every active notification endpoint of the org
+// and returns their health.
+func (s *NotificationEndpointService) CheckAll(ctx context.Context, orgID influxdb.ID) ([]influxdb.NotificationEndpointHealth, error) {
+	var resp notificationEndpointsHealthResponse
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.
+			Get(notificationEndpointsHealthPath).
+			QueryParams([2]string{"orgID", orgID.String()}).
+			DecodeJSON(&resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Endpoints, nil
+}
+
+// FindNotificationEndpointHistory returns the kept prior configurations of the
+// notification endpoint, oldest first.
+func (s *NotificationEndpointService) FindNotificationEndpointHistory(ctx context.Context, id influxdb.ID) ([]influxdb.NotificationEndpointVersion, error) {
+	var resp struct {
+		Versions []struct {
+			Version              int                         `json:"version"`
+			ReplacedAt           time.Time                   `json:"replacedAt"`
+			NotificationEndpoint notificationEndpointDecoder `json:"notificationEndpoint"`
+		} `json:"versions"`
+	}
+	err := s.doIdempotent(ctx, func() *httpc.Req {
+		return s.Client.
+			Get(prefixNotificationEndpoints, id.String(), "history").
+			DecodeJSON(&resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]influxdb.NotificationEndpointVersion, 0, len(resp.Versions))
+	for _, v := range resp.Versions {
+		versions = append(versions, influxdb.NotificationEndpointVersion{
+			Version:              v.Version,
+			ReplacedAt:           v.ReplacedAt,
+			NotificationEndpoint: v.NotificationEndpoint.endpoint,
+		})
+	}
+	return versions, nil
+}
+
+// RestoreNotificationEndpointVersion updates the notification endpoint back to a kept
+// prior configuration. The user is taken from the client's credentials.
+func (s *NotificationEndpointService) RestoreNotificationEndpointVersion(ctx context.Context, id influxdb.ID, version int, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	var resp notificationEndpointDecoder
+	err := s.Client.
+		Post(httpc.BodyEmpty, prefixNotificationEndpoints, id.String(), "history", strconv.Itoa(version), "restore").
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.endpoint, nil
+}
+
+// bodyRawJSON writes already encoded json as the request body.
+func bodyRawJSON(b []byte) httpc.BodyFn {
+	return func(w io.Writer) (string, string, error) {
+		_, err := w.Write(b)
+		return "Content-Type", "application/json", err
+	}
+}
+
 type notificationEndpointDecoder struct {
 	endpoint influxdb.NotificationEndpoint
 }
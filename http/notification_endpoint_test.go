@@ -1,27 +1,40 @@
 package http
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb"
 	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/endpoints"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/kv"
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
 	"github.com/influxdata/influxdb/pkg/testttp"
 	influxTesting "github.com/influxdata/influxdb/testing"
 	platformtesting "github.com/influxdata/influxdb/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // NewMockNotificationEndpointBackend returns a NotificationEndpointBackend with mock services.
@@ -34,6 +47,7 @@ func NewMockNotificationEndpointBackend(t *testing.T) *NotificationEndpointBacke
 		LabelService:                mock.NewLabelService(),
 		UserService:                 mock.NewUserService(),
 		OrganizationService:         mock.NewOrganizationService(),
+		SecretService:               mock.NewSecretService(),
 	}
 }
 
@@ -48,6 +62,7 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 	type wants struct {
 		statusCode  int
 		contentType string
+		headers     map[string]string
 		body        string
 	}
 
@@ -116,6 +131,11 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 			wants: wants{
 				statusCode:  http.StatusOK,
 				contentType: "application/json; charset=utf-8",
+				headers: map[string]string{
+					"X-Total-Count": "2",
+					"X-Page-Limit":  "1",
+					"X-Page-Offset": "0",
+				},
 				body: `
 		{
 		  "links": {
@@ -124,7 +144,6 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 		  },
 		  "notificationEndpoints": [
 		   {
-		     "createdAt": "0001-01-01T00:00:00Z",
 		     "id": "0b501e7e557ab1ed",
 		     "labels": [
 		       {
@@ -145,12 +164,9 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 		     "orgID": "50f7ba1150f7ba11",
 		     "status": "active",
 			 "type": "slack",
-			 "token": "",
-		     "updatedAt": "0001-01-01T00:00:00Z",
 		     "url": "http://example.com"
 		   },
 		   {
-		     "createdAt": "0001-01-01T00:00:00Z",
 		     "url": "example.com",
 		     "id": "c0175f0077a77005",
 		     "labels": [
@@ -173,7 +189,6 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 			 "authMethod": "basic",
              "contentTemplate": "template",
 			 "password": "secret: http-password-key",
-			 "token":"",
   			 "method": "POST",
 		     "status": "inactive",
 			 "type": "http",
@@ -181,7 +196,6 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 				"x-header-1": "header 1",
 				"x-header-2": "header 2"
 			 },
-		     "updatedAt": "0001-01-01T00:00:00Z",
 		     "username": "secret: http-user-key"
 		   }
 		   ]
@@ -206,6 +220,11 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 			wants: wants{
 				statusCode:  http.StatusOK,
 				contentType: "application/json; charset=utf-8",
+				headers: map[string]string{
+					"X-Total-Count": "0",
+					"X-Page-Limit":  "1",
+					"X-Page-Offset": "0",
+				},
 				body: `
 {
   "links": {
@@ -249,6 +268,11 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 			if tt.wants.contentType != "" && content != tt.wants.contentType {
 				t.Errorf("%q. handleGetNotificationEndpoints() = %v, want %v", tt.name, content, tt.wants.contentType)
 			}
+			for k, v := range tt.wants.headers {
+				if got := res.Header.Get(k); got != v {
+					t.Errorf("%q. handleGetNotificationEndpoints() header %s = %v, want %v", tt.name, k, got, v)
+				}
+			}
 			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil || tt.wants.body != "" && !eq {
 				t.Errorf("%q. handleGetNotificationEndpoints() = ***%v***", tt.name, diff)
 			}
@@ -256,6 +280,99 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 	}
 }
 
+func TestService_handleGetNotificationEndpoints_Descending(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	for _, name := range []string{"edp-a", "edp-b", "edp-c"} {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				Name:   name,
+				OrgID:  &org.ID,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x/y/z",
+		}
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	testttp.
+		Get(t, prefixNotificationEndpoints+"?orgID="+org.ID.String()+"&sortBy=name&descending=true&limit=2").
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				Links                 influxdb.PagingLinks `json:"links"`
+				NotificationEndpoints []struct {
+					Name string `json:"name"`
+				} `json:"notificationEndpoints"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+
+			require.Len(t, resp.NotificationEndpoints, 2)
+			assert.Equal(t, "edp-c", resp.NotificationEndpoints[0].Name)
+			assert.Equal(t, "edp-b", resp.NotificationEndpoints[1].Name)
+
+			assert.Equal(t, "/api/v2/notificationEndpoints?descending=true&limit=2&offset=0&orgID="+org.ID.String()+"&sortBy=name", resp.Links.Self)
+			assert.Equal(t, "/api/v2/notificationEndpoints?descending=true&limit=2&offset=2&orgID="+org.ID.String()+"&sortBy=name", resp.Links.Next)
+		})
+}
+
+func TestService_handleGetNotificationEndpoints_TotalCount(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	for _, name := range []string{"edp-a", "edp-b", "edp-c", "edp-d", "edp-e"} {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				Name:   name,
+				OrgID:  &org.ID,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x/y/z",
+		}
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	testttp.
+		Get(t, prefixNotificationEndpoints+"?orgID="+org.ID.String()+"&limit=1&offset=1").
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("X-Total-Count", "5").
+		ExpectHeader("X-Page-Limit", "1").
+		ExpectHeader("X-Page-Offset", "1").
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				NotificationEndpoints []struct {
+					Name string `json:"name"`
+				} `json:"notificationEndpoints"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			require.Len(t, resp.NotificationEndpoints, 1)
+			assert.Equal(t, "edp-b", resp.NotificationEndpoints[0].Name)
+		})
+}
+
 func TestService_handleGetNotificationEndpoint(t *testing.T) {
 	type fields struct {
 		NotificationEndpointService influxdb.NotificationEndpointService
@@ -318,13 +435,10 @@ func TestService_handleGetNotificationEndpoint(t *testing.T) {
 		  "authMethod": "basic",
 		  "method": "POST",
 		  "contentTemplate": "template",
-		  "createdAt": "0001-01-01T00:00:00Z",
-		  "updatedAt": "0001-01-01T00:00:00Z",
 		  "id": "020f755c3c082000",
 		  "url": "example.com",
 		  "username": "secret: http-user-key",
 		  "password": "secret: http-password-key",
-		  "token":"",
 		  "status": "active",
           "type": "http",
 		  "orgID": "020f755c3c082000",
@@ -399,6 +513,33 @@ func TestService_handleGetNotificationEndpoint(t *testing.T) {
 	}
 }
 
+func TestService_handleGetNotificationEndpointTypes(t *testing.T) {
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), NewMockNotificationEndpointBackend(t))
+
+	testttp.
+		Get(t, notificationEndpointsTypesPath).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				Types []endpoint.TypeInfo `json:"types"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+
+			byType := make(map[string]endpoint.TypeInfo)
+			for _, typ := range resp.Types {
+				byType[typ.Type] = typ
+			}
+			require.Contains(t, byType, endpoint.SlackType)
+			require.Contains(t, byType, endpoint.HTTPType)
+
+			assert.Equal(t, []string{"token"}, byType[endpoint.SlackType].SecretFields)
+			assert.Equal(t, []string{"token", "username", "password", "caCert"}, byType[endpoint.HTTPType].SecretFields)
+			assert.NotEmpty(t, byType[endpoint.HTTPType].DisplayName)
+			assert.Contains(t, byType[endpoint.HTTPType].RequiredFields, "url")
+		})
+}
+
 func TestService_handlePostNotificationEndpoint(t *testing.T) {
 	type fields struct {
 		Secrets                     map[string]string
@@ -468,13 +609,10 @@ func TestService_handlePostNotificationEndpoint(t *testing.T) {
   "status": "active",
   "username": "secret: 020f755c3c082000-username",
   "password": "secret: 020f755c3c082000-password",
-  "token":"",
   "authMethod": "basic",
   "contentTemplate": "template",
   "type": "http",
   "method": "POST",
-  "createdAt": "0001-01-01T00:00:00Z",
-  "updatedAt": "0001-01-01T00:00:00Z",
   "id": "020f755c3c082000",
   "orgID": "6f626f7274697320",
   "name": "hello",
@@ -512,6 +650,225 @@ func TestService_handlePostNotificationEndpoint(t *testing.T) {
 	}
 }
 
+func TestService_handlePostNotificationEndpoint_FieldRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	slackBody := map[string]interface{}{
+		"type": endpoint.SlackType,
+		"url":  "https://hooks.slack.com/services/x/y/z",
+	}
+	slackBotBody := map[string]interface{}{
+		"type":  endpoint.SlackType,
+		"url":   "https://slack.com/api/chat.postMessage",
+		"token": "bot-token",
+	}
+	httpBody := map[string]interface{}{
+		"type":       endpoint.HTTPType,
+		"url":        "https://example.com/hook",
+		"method":     "POST",
+		"authMethod": "none",
+	}
+	pagerDutyBody := map[string]interface{}{
+		"type":       endpoint.PagerDutyType,
+		"clientURL":  "https://example.com",
+		"routingKey": "rk1",
+	}
+
+	tests := []struct {
+		name    string
+		base    map[string]interface{}
+		valid   map[string]interface{}
+		want    map[string]interface{} // defaults to valid
+		invalid map[string]interface{}
+		// computed are nested response fields the service fills in, they are
+		// checked to be set and left out of the comparison.
+		computed []string
+	}{
+		{
+			name:    "slack bot channels",
+			base:    slackBotBody,
+			valid:   map[string]interface{}{"channels": []string{"#alerts", "#oncall"}},
+			invalid: map[string]interface{}{"channels": nil},
+		},
+		{
+			name: "slack bot thread replies",
+			base: slackBotBody,
+			valid: map[string]interface{}{
+				"channels":       []string{"#alerts"},
+				"threadTS":       "1503435956.000247",
+				"broadcastReply": true,
+			},
+			invalid: map[string]interface{}{
+				"channels":       []string{"#alerts"},
+				"broadcastReply": true,
+			},
+		},
+		{
+			name:    "max payload bytes",
+			base:    httpBody,
+			valid:   map[string]interface{}{"maxPayloadBytes": 65536},
+			invalid: map[string]interface{}{"maxPayloadBytes": -1},
+		},
+		{
+			name:    "default tags with a reserved key",
+			base:    slackBody,
+			valid:   map[string]interface{}{"defaultTags": map[string]string{"datacenter": "us-east"}},
+			invalid: map[string]interface{}{"defaultTags": map[string]string{"_level": "crit"}},
+		},
+		{
+			name:    "field mapping with duplicate keys",
+			base:    httpBody,
+			valid:   map[string]interface{}{"fieldMapping": map[string]string{"_message": "text", "_level": "severity"}},
+			invalid: map[string]interface{}{"fieldMapping": map[string]string{"_message": "text", "_level": "text"}},
+		},
+		{
+			name:    "dedup key template",
+			base:    pagerDutyBody,
+			valid:   map[string]interface{}{"dedupKeyTemplate": "{{.host}}-{{._check_id}}"},
+			invalid: map[string]interface{}{"dedupKeyTemplate": "{{.host"},
+		},
+		{
+			name:    "compress payload of a GET",
+			base:    httpBody,
+			valid:   map[string]interface{}{"compressPayload": true},
+			invalid: map[string]interface{}{"compressPayload": true, "method": http.MethodGet},
+		},
+		{
+			name:    "max notifications per hour",
+			base:    slackBody,
+			valid:   map[string]interface{}{"maxNotificationsPerHour": 20},
+			invalid: map[string]interface{}{"maxNotificationsPerHour": -1},
+		},
+		{
+			name:     "secret rotation",
+			base:     pagerDutyBody,
+			valid:    map[string]interface{}{"secretRotation": map[string]interface{}{"interval": "720h"}},
+			want:     map[string]interface{}{"secretRotation": map[string]interface{}{"interval": "720h0m0s"}},
+			invalid:  map[string]interface{}{"secretRotation": map[string]interface{}{"interval": "30m"}},
+			computed: []string{"secretRotation.nextRotationAt"},
+		},
+		{
+			name:    "min severity",
+			base:    slackBody,
+			valid:   map[string]interface{}{"minSeverity": "CRIT"},
+			want:    map[string]interface{}{"minSeverity": "crit"},
+			invalid: map[string]interface{}{"minSeverity": "fatal"},
+		},
+		{
+			name:    "on-call schedule id",
+			base:    pagerDutyBody,
+			valid:   map[string]interface{}{"onCallScheduleID": " PABC123 "},
+			want:    map[string]interface{}{"onCallScheduleID": "PABC123"},
+			invalid: map[string]interface{}{"onCallScheduleID": strings.Repeat("x", 257)},
+		},
+		{
+			name:    "batching",
+			base:    slackBody,
+			valid:   map[string]interface{}{"batchWindow": "10m", "maxBatchSize": 50},
+			invalid: map[string]interface{}{"batchWindow": "10m", "maxBatchSize": -1},
+		},
+		{
+			name:    "success status codes",
+			base:    httpBody,
+			valid:   map[string]interface{}{"successStatusCodes": []int{202, 204}},
+			invalid: map[string]interface{}{"successStatusCodes": []int{42}},
+		},
+		{
+			name:    "failover urls",
+			base:    httpBody,
+			valid:   map[string]interface{}{"failoverURLs": []string{"https://us-west.example.com/hook", "https://eu.example.com/hook"}},
+			invalid: map[string]interface{}{"failoverURLs": []string{"http://[::1"}},
+		},
+		{
+			name:    "ack callback url",
+			base:    slackBody,
+			valid:   map[string]interface{}{"ackCallbackURL": "https://influx.example.com/api/v2/acks"},
+			invalid: map[string]interface{}{"ackCallbackURL": "/api/v2/acks"},
+		},
+	}
+
+	newBody := func(name string, base, fields map[string]interface{}) map[string]interface{} {
+		body := map[string]interface{}{
+			"name":   name,
+			"orgID":  org.ID.String(),
+			"status": "active",
+		}
+		for k, v := range base {
+			body[k] = v
+		}
+		for k, v := range fields {
+			body[k] = v
+		}
+		return body
+	}
+
+	// toJSONValue decodes v the way a response body field is decoded, so values
+	// built from go types compare equal to the ones read back.
+	toJSONValue := func(t *testing.T, v interface{}) interface{} {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		var out interface{}
+		require.NoError(t, json.Unmarshal(b, &out))
+		return out
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id influxdb.ID
+			testttp.
+				PostJSON(t, prefixNotificationEndpoints, newBody(tt.name, tt.base, tt.valid)).
+				WrapCtx(authCtxFn(user1ID)).
+				Do(h).
+				ExpectStatus(http.StatusCreated).
+				ExpectBody(func(body *bytes.Buffer) {
+					var resp struct {
+						ID influxdb.ID `json:"id"`
+					}
+					require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+					id = resp.ID
+				})
+
+			want := tt.want
+			if want == nil {
+				want = tt.valid
+			}
+			testttp.
+				Get(t, path.Join(prefixNotificationEndpoints, id.String())).
+				Do(h).
+				ExpectStatus(http.StatusOK).
+				ExpectBody(func(body *bytes.Buffer) {
+					var resp map[string]interface{}
+					require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+					for _, c := range tt.computed {
+						parts := strings.SplitN(c, ".", 2)
+						obj, ok := resp[parts[0]].(map[string]interface{})
+						require.True(t, ok, "expected %s to be an object", parts[0])
+						assert.Contains(t, obj, parts[1])
+						delete(obj, parts[1])
+					}
+					for k, v := range want {
+						assert.Equal(t, toJSONValue(t, v), resp[k], k)
+					}
+				})
+
+			testttp.
+				PostJSON(t, prefixNotificationEndpoints, newBody(tt.name+" invalid", tt.base, tt.invalid)).
+				WrapCtx(authCtxFn(user1ID)).
+				Do(h).
+				ExpectStatus(http.StatusBadRequest)
+		})
+	}
+}
+
 func TestService_handleDeleteNotificationEndpoint(t *testing.T) {
 	type fields struct {
 		NotificationEndpointService influxdb.NotificationEndpointService
@@ -534,6 +891,11 @@ func TestService_handleDeleteNotificationEndpoint(t *testing.T) {
 			name: "remove a notification endpoint by id",
 			fields: fields{
 				NotificationEndpointService: &mock.NotificationEndpointService{
+					FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+						return &endpoint.Slack{
+							Base: endpoint.Base{ID: &id, Name: "slack"},
+						}, nil
+					},
 					DeleteNotificationEndpointF: func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
 						if id == influxTesting.MustIDBase16("020f755c3c082000") {
 							return []influxdb.SecretField{
@@ -556,8 +918,8 @@ func TestService_handleDeleteNotificationEndpoint(t *testing.T) {
 			name: "notification endpoint not found",
 			fields: fields{
 				NotificationEndpointService: &mock.NotificationEndpointService{
-					DeleteNotificationEndpointF: func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
-						return nil, 0, &influxdb.Error{
+					FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+						return nil, &influxdb.Error{
 							Code: influxdb.ENotFound,
 							Msg:  "notification endpoint not found",
 						}
@@ -658,16 +1020,14 @@ func TestService_handlePatchNotificationEndpoint(t *testing.T) {
 		    "members": "/api/v2/notificationEndpoints/020f755c3c082000/members",
 		    "owners": "/api/v2/notificationEndpoints/020f755c3c082000/owners"
 		  },
-		  "createdAt": "0001-01-01T00:00:00Z",
-		  "updatedAt": "0001-01-01T00:00:00Z",
 		  "id": "020f755c3c082000",
 		  "orgID": "020f755c3c082000",
 		  "url": "http://example.com",
 		  "name": "example",
 		  "status": "active",
 		  "type": "slack",
-		  "token": "",
-		  "labels": []
+		  "labels": [],
+		  "warnings": ["insecure transport: url uses http, notifications are sent unencrypted"]
 		}
 		`,
 			},
@@ -806,15 +1166,12 @@ func TestService_handleUpdateNotificationEndpoint(t *testing.T) {
 		    "members": "/api/v2/notificationEndpoints/020f755c3c082000/members",
 		    "owners": "/api/v2/notificationEndpoints/020f755c3c082000/owners"
 		  },
-		  "createdAt": "0001-01-01T00:00:00Z",
-		  "updatedAt": "0001-01-01T00:00:00Z",
 		  "id": "020f755c3c082000",
 		  "orgID": "020f755c3c082001",
 		  "name": "example",
 		  "url": "example.com",
           "type": "slack",
 		  "status": "active",
-		  "token": "",
           "labels": []
 		}
 		`,
@@ -1061,6 +1418,417 @@ func TestService_handlePostNotificationEndpointOwner(t *testing.T) {
 	}
 }
 
+func TestNotificationEndpointHandler_RequestID(t *testing.T) {
+	newHandler := func(t *testing.T) (*NotificationEndpointHandler, *observer.ObservedLogs) {
+		core, logs := observer.New(zap.DebugLevel)
+		backend := NewMockNotificationEndpointBackend(t)
+		backend.NotificationEndpointService = &mock.NotificationEndpointService{
+			FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+				return &endpoint.Slack{
+					Base: endpoint.Base{
+						ID:     &id,
+						OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+						Name:   "hello",
+						Status: influxdb.Active,
+					},
+					URL: "http://example.com",
+				}, nil
+			},
+		}
+		return NewNotificationEndpointHandler(zap.New(core), backend), logs
+	}
+
+	t.Run("provided request id is logged and echoed", func(t *testing.T) {
+		h, logs := newHandler(t)
+
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000")).
+			Headers(RequestIDHeader, "req-1234").
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader(RequestIDHeader, "req-1234")
+
+		entries := logs.FilterField(zap.String("request_id", "req-1234")).All()
+		require.NotEmpty(t, entries)
+		assert.Equal(t, "NotificationEndpoint retrieved", entries[0].Message)
+	})
+
+	t.Run("request id is generated when missing", func(t *testing.T) {
+		h, logs := newHandler(t)
+
+		resp := testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+
+		reqID := resp.Rec.Header().Get(RequestIDHeader)
+		require.NotEmpty(t, reqID)
+		assert.NotEmpty(t, logs.FilterField(zap.String("request_id", reqID)).All())
+	})
+}
+
+func TestNotificationEndpointHandler_LogsOrgID(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	core, logs := observer.New(zap.DebugLevel)
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zap.New(core), backend)
+
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, map[string]interface{}{
+			"type":   endpoint.SlackType,
+			"name":   "slack",
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/x/y/z",
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated)
+
+	entries := logs.FilterMessage("NotificationEndpoint created").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, org.ID.String(), entries[0].ContextMap()["orgID"])
+}
+
+func TestService_handleCloneNotificationEndpointsToOrg(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	srcOrg := &influxdb.Organization{Name: "template"}
+	dstOrg := &influxdb.Organization{Name: "new-org"}
+	for _, o := range []*influxdb.Organization{srcOrg, dstOrg} {
+		require.NoError(t, svc.CreateOrganization(ctx, o))
+	}
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	slack := &endpoint.Slack{
+		Base: endpoint.Base{
+			Name:   "slack",
+			OrgID:  &srcOrg.ID,
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("slack-token")},
+		Channels: []string{"#alerts"},
+	}
+	httpEdp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			Name:   "http",
+			OrgID:  &srcOrg.ID,
+			Status: influxdb.Active,
+		},
+		URL:        "https://example.com",
+		Method:     http.MethodPost,
+		AuthMethod: "basic",
+		Username:   influxdb.SecretField{Value: strPtr("user")},
+		Password:   influxdb.SecretField{Value: strPtr("pass")},
+	}
+	for _, edp := range []influxdb.NotificationEndpoint{slack, httpEdp} {
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+	}
+
+	label := &influxdb.Label{OrgID: srcOrg.ID, Name: "managed", Properties: map[string]string{"color": "fff000"}}
+	require.NoError(t, svc.CreateLabel(ctx, label))
+	require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+		LabelID:      label.ID,
+		ResourceID:   slack.GetID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+	}))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	auth := &influxdb.Authorization{
+		UserID:      user1ID,
+		Status:      influxdb.Active,
+		Permissions: append(influxdb.OwnerPermissions(srcOrg.ID), influxdb.OwnerPermissions(dstOrg.ID)...),
+	}
+
+	t.Run("requires write access to both orgs", func(t *testing.T) {
+		readOnly := &influxdb.Authorization{
+			UserID:      user1ID,
+			Status:      influxdb.Active,
+			Permissions: influxdb.OwnerPermissions(dstOrg.ID),
+		}
+		testttp.
+			PostJSON(t, notificationEndpointsCloneToOrgPath, map[string]string{
+				"sourceOrgID": srcOrg.ID.String(),
+				"targetOrgID": dstOrg.ID.String(),
+			}).
+			WrapCtx(func(ctx context.Context) context.Context {
+				return pcontext.SetAuthorizer(ctx, readOnly)
+			}).
+			Do(h).
+			ExpectStatus(http.StatusUnauthorized)
+	})
+
+	testttp.
+		PostJSON(t, notificationEndpointsCloneToOrgPath, map[string]string{
+			"sourceOrgID": srcOrg.ID.String(),
+			"targetOrgID": dstOrg.ID.String(),
+		}).
+		WrapCtx(func(ctx context.Context) context.Context {
+			return pcontext.SetAuthorizer(ctx, auth)
+		}).
+		Do(h).
+		ExpectStatus(http.StatusCreated)
+
+	cloned, _, err := endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &dstOrg.ID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, cloned, 2)
+
+	for _, edp := range cloned {
+		assert.NotEqual(t, slack.GetID(), edp.GetID())
+		assert.NotEqual(t, httpEdp.GetID(), edp.GetID())
+
+		flds := edp.SecretFields()
+		require.NotEmpty(t, flds)
+		for _, fld := range flds {
+			assert.True(t, strings.HasPrefix(fld.Key, edp.GetID().String()))
+			v, err := svc.LoadSecret(ctx, dstOrg.ID, fld.Key)
+			require.NoError(t, err)
+			assert.Empty(t, v, "secret %s should be an empty placeholder", fld.Key)
+		}
+
+		labels, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
+		require.NoError(t, err)
+		if edp.GetName() == "slack" {
+			require.Len(t, labels, 1)
+			assert.Equal(t, "managed", labels[0].Name)
+			assert.Equal(t, dstOrg.ID, labels[0].OrgID)
+		} else {
+			assert.Empty(t, labels)
+		}
+	}
+}
+
+func TestService_handlePostNotificationEndpointsDiff(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	keep := &endpoint.Slack{
+		Base: endpoint.Base{
+			Name:   "keep",
+			OrgID:  &org.ID,
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("slack-token")},
+		Channels: []string{"#alerts"},
+	}
+	remove := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			Name:   "remove",
+			OrgID:  &org.ID,
+			Status: influxdb.Active,
+		},
+		ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey: influxdb.SecretField{Value: strPtr("routing-key")},
+	}
+	for _, edp := range []influxdb.NotificationEndpoint{keep, remove} {
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	bundle := map[string]interface{}{
+		"notificationEndpoints": []map[string]interface{}{
+			{
+				"type":     endpoint.SlackType,
+				"name":     "keep",
+				"status":   "active",
+				"url":      "https://slack.com/api/chat.postMessage/",
+				"token":    "another-slack-token",
+				"channels": []string{"#alerts"},
+			},
+			{
+				"type":       endpoint.HTTPType,
+				"name":       "add",
+				"status":     "active",
+				"url":        "https://example.com",
+				"method":     http.MethodPost,
+				"authMethod": "bearer",
+				"token":      "http-token",
+			},
+		},
+	}
+
+	testttp.
+		PostJSON(t, notificationEndpointsDiffPath+"?orgID="+org.ID.String(), bundle).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			assert.NotContains(t, body.String(), "http-token")
+
+			var resp struct {
+				Creates []json.RawMessage `json:"creates"`
+				Updates []json.RawMessage `json:"updates"`
+				Deletes []json.RawMessage `json:"deletes"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			require.Len(t, resp.Creates, 1)
+			assert.Empty(t, resp.Updates)
+			require.Len(t, resp.Deletes, 1)
+
+			created, err := endpoint.UnmarshalJSON(resp.Creates[0])
+			require.NoError(t, err)
+			assert.Equal(t, "add", created.GetName())
+			assert.Equal(t, org.ID, created.GetOrgID())
+
+			deleted, err := endpoint.UnmarshalJSON(resp.Deletes[0])
+			require.NoError(t, err)
+			assert.Equal(t, remove.GetID(), deleted.GetID())
+		})
+
+	_, n, err := endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &org.ID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "diff must not apply any change")
+}
+
+func TestService_handlePostNotificationEndpointRotateSecret(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	slack := &endpoint.Slack{
+		Base: endpoint.Base{
+			Name:   "slack",
+			OrgID:  &org.ID,
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("old-token")},
+		Channels: []string{"#alerts"},
+	}
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, slack, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.SecretService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	rotatePath := path.Join(prefixNotificationEndpoints, slack.GetID().String(), "rotateSecret")
+
+	t.Run("rotates the slack token", func(t *testing.T) {
+		testttp.
+			PostJSON(t, rotatePath, map[string]string{
+				"secretKeySuffix": "-token",
+				"newValue":        "new-token",
+			}).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusNoContent)
+
+		v, err := svc.LoadSecret(ctx, org.ID, slack.Token.Key)
+		require.NoError(t, err)
+		assert.Equal(t, "new-token", v)
+		assert.NotEqual(t, "old-token", v)
+
+		edp, err := svc.FindNotificationEndpointByID(ctx, slack.GetID())
+		require.NoError(t, err)
+		assert.True(t, slack.GetCRUDLog().UpdatedAt.Equal(edp.GetCRUDLog().UpdatedAt))
+	})
+
+	t.Run("rejects a suffix the endpoint does not own", func(t *testing.T) {
+		testttp.
+			PostJSON(t, rotatePath, map[string]string{
+				"secretKeySuffix": "-routing-key",
+				"newValue":        "nope",
+			}).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handlePostNotificationEndpointRotateSecret_NamedStore(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+	kms := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc, endpoints.WithSecretStore("kms", kms))
+	slack := &endpoint.Slack{
+		Base: endpoint.Base{
+			Name:   "slack",
+			OrgID:  &org.ID,
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("old-token"), Store: "kms"},
+		Channels: []string{"#alerts"},
+	}
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, slack, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.EndpointSecretService = endpointSVC
+	backend.SecretService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	ownerCtx := func(ctx context.Context) context.Context {
+		return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+			Status:      influxdb.Active,
+			UserID:      user1ID,
+			OrgID:       org.ID,
+			Permissions: influxdb.OwnerPermissions(org.ID),
+		})
+	}
+
+	testttp.
+		PostJSON(t, path.Join(prefixNotificationEndpoints, slack.GetID().String(), "rotateSecret"), map[string]string{
+			"secretKeySuffix": "-token",
+			"newValue":        "new-token",
+		}).
+		WrapCtx(ownerCtx).
+		Do(h).
+		ExpectStatus(http.StatusNoContent)
+
+	v, err := kms.LoadSecret(ctx, org.ID, slack.Token.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "new-token", v)
+
+	_, err = svc.LoadSecret(ctx, org.ID, slack.Token.Key)
+	assert.Error(t, err, "the token must not be written to the default store")
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
 func initNotificationEndpointService(f platformtesting.NotificationEndpointFields, t *testing.T) (influxdb.NotificationEndpointService, influxdb.SecretService, func()) {
 	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
 	svc.IDGenerator = f.IDGenerator
@@ -1134,3 +1902,2982 @@ func authCtxFn(userID influxdb.ID) func(context.Context) context.Context {
 		return pcontext.SetAuthorizer(ctx, &influxdb.Session{UserID: userID})
 	}
 }
+
+func TestService_handlePostNotificationEndpoint_IfNotExists(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	existing := &endpoint.Slack{
+		Base: endpoint.Base{
+			Name:   "slack",
+			OrgID:  &org.ID,
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, existing, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newBody := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":   endpoint.SlackType,
+			"name":   name,
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/a/b/c",
+		}
+	}
+
+	countEndpoints := func(t *testing.T) int {
+		t.Helper()
+		_, n, err := endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &org.ID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("returns the existing endpoint", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?ifNotExists=true", newBody("slack")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					ID  influxdb.ID `json:"id"`
+					URL string      `json:"url"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Equal(t, existing.GetID(), resp.ID)
+				assert.Equal(t, existing.URL, resp.URL)
+			})
+
+		assert.Equal(t, 1, countEndpoints(t))
+	})
+
+	t.Run("creates the endpoint when none exists", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?ifNotExists=true", newBody("slack-2")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated)
+
+		assert.Equal(t, 2, countEndpoints(t))
+	})
+
+	t.Run("rejects an invalid flag", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?ifNotExists=maybe", newBody("slack-3")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handlePostNotificationEndpoint_LabelNames(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	existing := &influxdb.Label{OrgID: org.ID, Name: "existing"}
+	require.NoError(t, svc.CreateLabel(ctx, existing))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newBody := func(name string, labelNames ...string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":       endpoint.SlackType,
+			"name":       name,
+			"orgID":      org.ID.String(),
+			"status":     "active",
+			"url":        "https://hooks.slack.com/services/a/b/c",
+			"labelNames": labelNames,
+		}
+	}
+
+	t.Run("creates missing labels when asked to", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?createMissingLabels=true", newBody("slack-1", "existing", "new")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					ID     influxdb.ID      `json:"id"`
+					Labels []influxdb.Label `json:"labels"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				require.Len(t, resp.Labels, 2)
+				assert.Equal(t, existing.ID, resp.Labels[0].ID)
+				assert.Equal(t, "new", resp.Labels[1].Name)
+
+				mapped, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: resp.ID})
+				require.NoError(t, err)
+				assert.Len(t, mapped, 2)
+			})
+
+		labels, err := svc.FindLabels(ctx, influxdb.LabelFilter{OrgID: &org.ID, Name: "new"})
+		require.NoError(t, err)
+		assert.Len(t, labels, 1)
+	})
+
+	t.Run("rejects missing labels by default", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("slack-2", "missing")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusNotFound)
+	})
+}
+
+func TestService_handleDeleteNotificationEndpoint_Secrets(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newHTTPEndpoint := func(t *testing.T, name string) *endpoint.HTTP {
+		t.Helper()
+		edp := &endpoint.HTTP{
+			Base: endpoint.Base{
+				Name:   name,
+				OrgID:  &org.ID,
+				Status: influxdb.Active,
+			},
+			URL:        "https://example.com",
+			Method:     http.MethodPost,
+			AuthMethod: "basic",
+			Username:   influxdb.SecretField{Value: strPtr("user")},
+			Password:   influxdb.SecretField{Value: strPtr("pass")},
+		}
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+		return edp
+	}
+
+	t.Run("removes the endpoint secrets", func(t *testing.T) {
+		edp := newHTTPEndpoint(t, "http-1")
+
+		testttp.
+			Delete(t, prefixNotificationEndpoints+"/"+edp.GetID().String()).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusNoContent)
+
+		keys, err := svc.GetSecretKeys(ctx, org.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, keys, edp.Username.Key)
+		assert.NotContains(t, keys, edp.Password.Key)
+	})
+
+	t.Run("keeps the endpoint secrets when asked to", func(t *testing.T) {
+		edp := newHTTPEndpoint(t, "http-2")
+
+		testttp.
+			Delete(t, prefixNotificationEndpoints+"/"+edp.GetID().String()+"?keepSecrets=true").
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusNoContent)
+
+		keys, err := svc.GetSecretKeys(ctx, org.ID)
+		require.NoError(t, err)
+		assert.Contains(t, keys, edp.Username.Key)
+		assert.Contains(t, keys, edp.Password.Key)
+	})
+}
+
+func TestService_handleDeleteNotificationEndpoint_DeleteProtected(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:           &org.ID,
+			Name:            "critical",
+			Status:          influxdb.Active,
+			DeleteProtected: true,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.HTTPErrorHandler = ErrorHandler(0)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	edpPath := path.Join(prefixNotificationEndpoints, edp.GetID().String())
+
+	testttp.
+		Delete(t, edpPath).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusUnprocessableEntity).
+		ExpectBody(func(body *bytes.Buffer) {
+			assert.Contains(t, body.String(), influxdb.EConflict)
+		})
+
+	_, err := svc.FindNotificationEndpointByID(ctx, edp.GetID())
+	require.NoError(t, err)
+
+	testttp.
+		PatchJSON(t, edpPath, map[string]interface{}{"deleteProtected": false}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			assert.NotContains(t, body.String(), "deleteProtected")
+		})
+
+	testttp.
+		Delete(t, edpPath).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusNoContent)
+
+	_, err = svc.FindNotificationEndpointByID(ctx, edp.GetID())
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+}
+
+func TestService_handlePostNotificationEndpointMute(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			Name:   "slack",
+			OrgID:  &org.ID,
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	mutePath := strings.Replace(notificationEndpointsIDMutePath, ":id", edp.GetID().String(), 1)
+	unmutePath := strings.Replace(notificationEndpointsIDUnmutePath, ":id", edp.GetID().String(), 1)
+
+	mutedUntil := func(t *testing.T) *time.Time {
+		t.Helper()
+		got, err := endpointSVC.FindNotificationEndpointByID(ctx, edp.GetID())
+		require.NoError(t, err)
+		return got.GetMutedUntil()
+	}
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		bodies := []map[string]interface{}{
+			{},
+			{"duration": "1h", "until": time.Now().Add(time.Hour)},
+			{"duration": "soon"},
+			{"duration": "-1h"},
+			{"until": time.Now().Add(-time.Hour)},
+		}
+		for _, body := range bodies {
+			testttp.
+				PostJSON(t, mutePath, body).
+				WrapCtx(authCtxFn(user1ID)).
+				Do(h).
+				ExpectStatus(http.StatusBadRequest)
+		}
+		assert.Nil(t, mutedUntil(t))
+	})
+
+	t.Run("mute until", func(t *testing.T) {
+		until := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+		testttp.
+			PostJSON(t, mutePath, map[string]interface{}{"until": until}).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+
+		got := mutedUntil(t)
+		require.NotNil(t, got)
+		assert.True(t, until.Equal(*got))
+	})
+
+	t.Run("unmute", func(t *testing.T) {
+		testttp.
+			Post(t, unmutePath, nil).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+
+		assert.Nil(t, mutedUntil(t))
+	})
+
+	t.Run("mute for duration expires", func(t *testing.T) {
+		testttp.
+			PostJSON(t, mutePath, map[string]interface{}{"duration": "1h"}).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+		require.NotNil(t, mutedUntil(t))
+
+		svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Now().Add(2 * time.Hour)}
+		defer func() { svc.TimeGenerator = influxdb.RealTimeGenerator{} }()
+
+		assert.Nil(t, mutedUntil(t))
+	})
+}
+
+func TestService_handleGetNotificationEndpointExport(t *testing.T) {
+	edp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			ID:     influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+			OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082001"),
+			Name:   "ops webhook",
+			Status: influxdb.Active,
+		},
+		URL:        "https://example.com/hook",
+		Method:     http.MethodPost,
+		AuthMethod: "bearer",
+		Token: influxdb.SecretField{
+			Key:   "020f755c3c082000-token",
+			Value: strPtr("super-secret-token"),
+		},
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	exportPath := strings.Replace(notificationEndpointsIDExportPath, ":id", edp.GetID().String(), 1)
+
+	t.Run("hcl", func(t *testing.T) {
+		testttp.
+			Get(t, exportPath+"?format=hcl").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", "text/plain; charset=utf-8").
+			ExpectBody(func(body *bytes.Buffer) {
+				hcl := body.String()
+				assert.Contains(t, hcl, `resource "influxdb_notification_endpoint" "ops_webhook" {`)
+				assert.Contains(t, hcl, `name = "ops webhook"`)
+				assert.Contains(t, hcl, `token = var.ops_webhook_token`)
+				assert.Contains(t, hcl, `variable "ops_webhook_token" {`)
+				assert.NotContains(t, hcl, "super-secret-token")
+			})
+	})
+
+	t.Run("json by default", func(t *testing.T) {
+		testttp.
+			Get(t, exportPath).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				got, err := endpoint.UnmarshalJSON(body.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, edp.GetName(), got.GetName())
+			})
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		testttp.
+			Get(t, exportPath+"?format=toml").
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handlePostNotificationEndpointPreview(t *testing.T) {
+	edp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			ID:     influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+			OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082001"),
+			Name:   "ops webhook",
+			Status: influxdb.Active,
+		},
+		URL:             "https://example.com/hook",
+		Method:          http.MethodPost,
+		AuthMethod:      "none",
+		ContentTemplate: `{"msg":"${r._message}","level":"${r._level}"}`,
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	previewPath := strings.Replace(notificationEndpointsIDPreviewPath, ":id", edp.GetID().String(), 1)
+
+	t.Run("renders the content template", func(t *testing.T) {
+		testttp.
+			PostJSON(t, previewPath, map[string]interface{}{
+				"alert": map[string]interface{}{
+					"_message": "cpu is high",
+					"_level":   "crit",
+				},
+			}).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var preview endpoint.Preview
+				require.NoError(t, json.Unmarshal(body.Bytes(), &preview))
+				assert.Equal(t, "https://example.com/hook", preview.URL)
+				assert.Equal(t, `{"msg":"cpu is high","level":"crit"}`, preview.Body)
+			})
+	})
+
+	t.Run("missing alert", func(t *testing.T) {
+		testttp.
+			PostJSON(t, previewPath, map[string]interface{}{}).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handleGetNotificationEndpoint_SecretReferencesHidden(t *testing.T) {
+	orgID := influxTesting.MustIDBase16("020f755c3c082001")
+	edp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			ID:     influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+			OrgID:  &orgID,
+			Name:   "ops webhook",
+			Status: influxdb.Active,
+		},
+		URL:        "https://example.com/hook",
+		Method:     http.MethodPost,
+		AuthMethod: "bearer",
+		Token:      influxdb.SecretField{Key: "020f755c3c082000-token"},
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+		UpdateNotificationEndpointF: func(ctx context.Context, id influxdb.ID, upd influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opts ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			return []influxdb.NotificationEndpoint{edp}, 1, nil
+		},
+	}
+	WithSecretReferencesHidden()(backend)
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	readerCtx := func(perms []influxdb.Permission) func(context.Context) context.Context {
+		return func(ctx context.Context) context.Context {
+			return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+				Status:      influxdb.Active,
+				UserID:      user1ID,
+				OrgID:       orgID,
+				Permissions: perms,
+			})
+		}
+	}
+	getToken := func(t *testing.T, perms []influxdb.Permission) (token, password string) {
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())).
+			WrapCtx(readerCtx(perms)).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					Token    string `json:"token"`
+					Password string `json:"password"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				token, password = resp.Token, resp.Password
+			})
+		return token, password
+	}
+
+	t.Run("admin sees secret references", func(t *testing.T) {
+		token, _ := getToken(t, influxdb.OwnerPermissions(orgID))
+		assert.Equal(t, "secret: 020f755c3c082000-token", token)
+	})
+
+	t.Run("member sees masked secret references", func(t *testing.T) {
+		token, password := getToken(t, influxdb.MemberPermissions(orgID))
+		assert.Equal(t, "***", token)
+		assert.Empty(t, password)
+	})
+
+	expectMaskedToken := func(t *testing.T) func(*bytes.Buffer) {
+		return func(body *bytes.Buffer) {
+			var resp struct {
+				Token string `json:"token"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			assert.Equal(t, "***", resp.Token)
+		}
+	}
+
+	t.Run("member sees masked secret references on update", func(t *testing.T) {
+		testttp.
+			PutJSON(t, path.Join(prefixNotificationEndpoints, edp.GetID().String()), edp).
+			WrapCtx(readerCtx(influxdb.MemberPermissions(orgID))).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(expectMaskedToken(t))
+	})
+
+	t.Run("member sees masked secret references on export", func(t *testing.T) {
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String(), "export")).
+			WrapCtx(readerCtx(influxdb.MemberPermissions(orgID))).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(expectMaskedToken(t))
+	})
+
+	t.Run("member sees masked secret references of diffed deletes", func(t *testing.T) {
+		testttp.
+			PostJSON(t, notificationEndpointsDiffPath+"?orgID="+orgID.String(), map[string]interface{}{
+				"notificationEndpoints": []interface{}{},
+			}).
+			WrapCtx(readerCtx(influxdb.MemberPermissions(orgID))).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					Deletes []json.RawMessage `json:"deletes"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				require.Len(t, resp.Deletes, 1)
+				expectMaskedToken(t)(bytes.NewBuffer(resp.Deletes[0]))
+			})
+	})
+}
+
+func TestService_handleGetNotificationEndpoints_Fields(t *testing.T) {
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:     influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+			OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082001"),
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL:   "https://slack.com/api/chat.postMessage",
+		Token: influxdb.SecretField{Key: "020f755c3c082000-token"},
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opts ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			return []influxdb.NotificationEndpoint{edp}, 1, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	t.Run("list", func(t *testing.T) {
+		testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&fields=id,name,unknown").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					NotificationEndpoints []map[string]interface{} `json:"notificationEndpoints"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				require.Len(t, resp.NotificationEndpoints, 1)
+				assert.Equal(t, map[string]interface{}{
+					"id":   "020f755c3c082000",
+					"name": "slack",
+				}, resp.NotificationEndpoints[0])
+			})
+	})
+
+	t.Run("get", func(t *testing.T) {
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())+"?fields=id,name").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				assert.JSONEq(t, `{"id":"020f755c3c082000","name":"slack"}`, body.String())
+			})
+	})
+}
+
+func TestService_handlePostNotificationEndpoint_Gzip(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	t.Run("gzipped body", func(t *testing.T) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		require.NoError(t, json.NewEncoder(gw).Encode(map[string]interface{}{
+			"type":   endpoint.SlackType,
+			"name":   "slack",
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/x/y/z",
+		}))
+		require.NoError(t, gw.Close())
+
+		testttp.
+			Post(t, prefixNotificationEndpoints, &buf).
+			Headers("Content-Encoding", "gzip").
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					Name string `json:"name"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Equal(t, "slack", resp.Name)
+			})
+	})
+
+	t.Run("malformed gzip body", func(t *testing.T) {
+		testttp.
+			Post(t, prefixNotificationEndpoints, strings.NewReader(`{"type":"slack"}`)).
+			Headers("Content-Encoding", "gzip").
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handlePostNotificationEndpoint_Warnings(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, map[string]interface{}{
+			"type":       endpoint.HTTPType,
+			"name":       "plain webhook",
+			"orgID":      org.ID.String(),
+			"status":     "active",
+			"url":        "http://example.com/hook",
+			"method":     "POST",
+			"authMethod": "bearer",
+			"token":      "t0k3n",
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				ID       influxdb.ID `json:"id"`
+				Warnings []string    `json:"warnings"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			require.Len(t, resp.Warnings, 1)
+			assert.Contains(t, resp.Warnings[0], "insecure transport")
+
+			_, err := svc.FindNotificationEndpointByID(ctx, resp.ID)
+			require.NoError(t, err)
+		})
+}
+
+func TestService_handlePostNotificationEndpoint_ClientProvidedID(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	WithClientProvidedIDs()(backend)
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	const providedID = "020f755c3c082abc"
+	newBody := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":     providedID,
+			"type":   endpoint.SlackType,
+			"name":   name,
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/x/y/z",
+		}
+	}
+
+	t.Run("provided id is used", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("slack")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					ID string `json:"id"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Equal(t, providedID, resp.ID)
+			})
+
+		edp, err := svc.FindNotificationEndpointByID(ctx, influxTesting.MustIDBase16(providedID))
+		require.NoError(t, err)
+		assert.Equal(t, "slack", edp.GetName())
+	})
+
+	t.Run("id collision is a conflict", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("other slack")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusUnprocessableEntity)
+	})
+}
+
+func TestService_handlePostNotificationEndpoint_BlockPrivateAddresses(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	WithBlockPrivateAddresses()(backend)
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newBody := func(name, url string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":       endpoint.HTTPType,
+			"name":       name,
+			"orgID":      org.ID.String(),
+			"status":     "active",
+			"url":        url,
+			"method":     "POST",
+			"authMethod": "none",
+		}
+	}
+
+	t.Run("metadata address is rejected", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("metadata", "http://169.254.169.254/latest/meta-data")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+
+		edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &org.ID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, edps)
+	})
+
+	t.Run("public address is created", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("public", "https://93.184.216.34/hook")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated)
+	})
+}
+
+func TestNotificationEndpointService_WithRetry(t *testing.T) {
+	const failures = 2
+
+	newFlakyServer := func(t *testing.T, status int, body string) (*httptest.Server, *int) {
+		t.Helper()
+		var calls int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}))
+		return srv, &calls
+	}
+
+	newService := func(t *testing.T, addr string, retries int) *NotificationEndpointService {
+		t.Helper()
+		client, err := NewHTTPClient(addr, "", false)
+		require.NoError(t, err)
+		return NewNotificationEndpointService(client, WithRetry(retries, time.Millisecond))
+	}
+
+	edpBody := `{"id":"020f755c3c082000","orgID":"020f755c3c082002","name":"slack","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"}`
+
+	t.Run("find by id succeeds after failures", func(t *testing.T) {
+		srv, calls := newFlakyServer(t, http.StatusOK, edpBody)
+		defer srv.Close()
+
+		edp, err := newService(t, srv.URL, 3).FindNotificationEndpointByID(context.Background(), influxTesting.MustIDBase16("020f755c3c082000"))
+		require.NoError(t, err)
+		assert.Equal(t, "slack", edp.GetName())
+		assert.Equal(t, failures+1, *calls)
+	})
+
+	t.Run("find succeeds after failures", func(t *testing.T) {
+		srv, calls := newFlakyServer(t, http.StatusOK, `{"notificationEndpoints":[`+edpBody+`]}`)
+		defer srv.Close()
+
+		edps, n, err := newService(t, srv.URL, 3).FindNotificationEndpoints(context.Background(), influxdb.NotificationEndpointFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, "slack", edps[0].GetName())
+		assert.Equal(t, failures+1, *calls)
+	})
+
+	t.Run("gives up once retries are exhausted", func(t *testing.T) {
+		srv, calls := newFlakyServer(t, http.StatusOK, edpBody)
+		defer srv.Close()
+
+		_, err := newService(t, srv.URL, 1).FindNotificationEndpointByID(context.Background(), influxTesting.MustIDBase16("020f755c3c082000"))
+		require.Error(t, err)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("does not retry create", func(t *testing.T) {
+		srv, calls := newFlakyServer(t, http.StatusCreated, edpBody)
+		defer srv.Close()
+
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				Name:   "slack",
+				OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082002"),
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x/y/z",
+		}
+		err := newService(t, srv.URL, 3).CreateNotificationEndpoint(context.Background(), edp, user1ID)
+		require.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestNotificationEndpointService_Create(t *testing.T) {
+	const newID = "020f755c3c082000"
+	orgID := influxTesting.MustIDBase16("020f755c3c082001")
+	now := time.Date(2006, time.July, 13, 4, 19, 10, 0, time.UTC)
+
+	s, _, done := initNotificationEndpointService(platformtesting.NotificationEndpointFields{
+		IDGenerator:   mock.NewIDGenerator(newID, t),
+		TimeGenerator: mock.TimeGenerator{FakeValue: now},
+		Orgs:          []*influxdb.Organization{{ID: orgID, Name: "org"}},
+	}, t)
+	defer done()
+	svc := s.(*NotificationEndpointService)
+
+	edp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			OrgID:  &orgID,
+			Name:   "hook",
+			Status: influxdb.Active,
+		},
+		URL:        "https://example.com/hook",
+		Method:     "POST",
+		AuthMethod: "none",
+	}
+	created, err := svc.Create(context.Background(), edp, user1ID)
+	require.NoError(t, err)
+
+	got, ok := created.(*endpoint.HTTP)
+	require.True(t, ok, "expected an http endpoint, got %T", created)
+	assert.Equal(t, influxTesting.MustIDBase16(newID), got.GetID())
+	assert.Equal(t, orgID, got.GetOrgID())
+	assert.Equal(t, "hook", got.Name)
+	assert.Equal(t, "https://example.com/hook", got.URL)
+	assert.True(t, now.Equal(got.CreatedAt))
+	assert.Nil(t, edp.ID, "Create must not mutate the endpoint passed in")
+}
+
+func TestNotificationEndpointService_ExportImport(t *testing.T) {
+	const (
+		orgID  = "020f755c3c082002"
+		bundle = `{"notificationEndpoints":[{"name":"slack","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z","token":""}]}`
+	)
+
+	var gotImport []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("orgID"); got != orgID {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == notificationEndpointsExportPath:
+			fmt.Fprint(w, bundle)
+		case r.Method == http.MethodPost && r.URL.Path == notificationEndpointsImportPath:
+			gotImport, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"notificationEndpoints":[{"id":"020f755c3c082000","orgID":"`+orgID+`","name":"slack","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "", false)
+	require.NoError(t, err)
+	svc := NewNotificationEndpointService(client)
+
+	exported, err := svc.Export(context.Background(), influxTesting.MustIDBase16(orgID))
+	require.NoError(t, err)
+	assert.JSONEq(t, bundle, string(exported))
+
+	edps, err := svc.Import(context.Background(), influxTesting.MustIDBase16(orgID), exported)
+	require.NoError(t, err)
+	assert.JSONEq(t, bundle, string(gotImport))
+	require.Len(t, edps, 1)
+	assert.Equal(t, "slack", edps[0].GetName())
+	assert.Equal(t, influxTesting.MustIDBase16("020f755c3c082000"), edps[0].GetID())
+}
+
+func TestService_handleNotificationEndpointsExportImport(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	src := &influxdb.Organization{Name: "src"}
+	require.NoError(t, svc.CreateOrganization(ctx, src))
+	dst := &influxdb.Organization{Name: "dst"}
+	require.NoError(t, svc.CreateOrganization(ctx, dst))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	require.NoError(t, backend.NotificationEndpointService.CreateNotificationEndpoint(ctx, &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &src.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("s3cr3t")},
+		Channels: []string{"#ops"},
+	}, user1ID))
+
+	var bundle []byte
+	testttp.
+		Get(t, notificationEndpointsExportPath+"?orgID="+src.ID.String()).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			bundle = body.Bytes()
+			assert.NotContains(t, body.String(), "s3cr3t")
+			assert.NotContains(t, body.String(), `"id"`)
+		})
+
+	testttp.
+		Post(t, notificationEndpointsImportPath+"?orgID="+dst.ID.String(), bytes.NewReader(bundle)).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated)
+
+	edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &dst.ID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, edps, 1)
+	assert.Equal(t, "slack", edps[0].GetName())
+	assert.Equal(t, []string{"#ops"}, edps[0].(*endpoint.Slack).Channels)
+}
+
+func TestService_handleOptionsNotificationEndpoint(t *testing.T) {
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), NewMockNotificationEndpointBackend(t))
+
+	tests := []struct {
+		name  string
+		path  string
+		allow string
+	}{
+		{
+			name:  "ID path",
+			path:  "/api/v2/notificationEndpoints/020f755c3c082000",
+			allow: "DELETE, GET, OPTIONS, PATCH, PUT",
+		},
+		{
+			name:  "collection path",
+			path:  prefixNotificationEndpoints,
+			allow: "GET, OPTIONS, POST",
+		},
+		{
+			name:  "action path",
+			path:  notificationEndpointsTypesPath,
+			allow: "GET, OPTIONS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testttp.
+				HTTP(t, http.MethodOptions, tt.path, nil).
+				Do(h).
+				ExpectStatus(http.StatusNoContent).
+				ExpectHeader("Allow", tt.allow)
+		})
+	}
+}
+
+func TestService_handlePostNotificationEndpointsDetect(t *testing.T) {
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), NewMockNotificationEndpointBackend(t))
+
+	t.Run("slack hooks url", func(t *testing.T) {
+		testttp.
+			PostJSON(t, notificationEndpointsDetectPath, map[string]string{
+				"url": "https://hooks.slack.com/services/T000/B000/XXXX",
+			}).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp detectNotificationEndpointResponse
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.True(t, resp.Detected)
+				assert.Equal(t, endpoint.SlackType, resp.Type)
+				assert.Equal(t, []string{"name", "status", "url"}, resp.RequiredFields)
+			})
+	})
+
+	t.Run("discord webhook url", func(t *testing.T) {
+		testttp.
+			PostJSON(t, notificationEndpointsDetectPath, map[string]string{
+				"url": "https://discord.com/api/webhooks/123/abc",
+			}).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp detectNotificationEndpointResponse
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.True(t, resp.Detected)
+				assert.Equal(t, endpoint.DiscordType, resp.Type)
+				assert.Empty(t, resp.SecretFields)
+			})
+	})
+
+	t.Run("unknown url falls back to http", func(t *testing.T) {
+		testttp.
+			PostJSON(t, notificationEndpointsDetectPath, map[string]string{
+				"url": "https://alerts.example.com/hook",
+			}).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp detectNotificationEndpointResponse
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.False(t, resp.Detected)
+				assert.Equal(t, endpoint.HTTPType, resp.Type)
+			})
+	})
+
+	t.Run("relative url is invalid", func(t *testing.T) {
+		testttp.
+			PostJSON(t, notificationEndpointsDetectPath, map[string]string{
+				"url": "hooks.slack.com/services",
+			}).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handleGetNotificationEndpoints_OutOfRangeOffset(t *testing.T) {
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opts ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			return []influxdb.NotificationEndpoint{}, 0, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	t.Run("empty page without a next link", func(t *testing.T) {
+		testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&offset=1000&limit=1").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					NotificationEndpoints []interface{}        `json:"notificationEndpoints"`
+					Links                 influxdb.PagingLinks `json:"links"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Empty(t, resp.NotificationEndpoints)
+				assert.Empty(t, resp.Links.Next)
+			})
+	})
+
+	t.Run("strict paging", func(t *testing.T) {
+		testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&offset=1000&strictPaging=true").
+			Do(h).
+			ExpectStatus(http.StatusBadRequest).
+			ExpectBody(func(body *bytes.Buffer) {
+				assert.Contains(t, body.String(), "offset 1000 is out of range")
+			})
+	})
+}
+
+func TestService_handlePostNotificationEndpointTransferOwnership(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	leaver := &influxdb.User{Name: "leaver"}
+	require.NoError(t, svc.CreateUser(ctx, leaver))
+	successor := &influxdb.User{Name: "successor"}
+	require.NoError(t, svc.CreateUser(ctx, successor))
+
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, svc.CreateNotificationEndpoint(ctx, edp, leaver.ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = svc
+	backend.ResourceOwnershipService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	transferPath := path.Join(prefixNotificationEndpoints, edp.GetID().String(), "transferOwnership")
+	body := map[string]string{
+		"fromUserID": leaver.ID.String(),
+		"toUserID":   successor.ID.String(),
+	}
+	authCtx := func(perms []influxdb.Permission) func(context.Context) context.Context {
+		return func(ctx context.Context) context.Context {
+			return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+				Status:      influxdb.Active,
+				OrgID:       org.ID,
+				Permissions: perms,
+			})
+		}
+	}
+
+	t.Run("members can't transfer ownership", func(t *testing.T) {
+		testttp.
+			PostJSON(t, transferPath, body).
+			WrapCtx(authCtx(influxdb.MemberPermissions(org.ID))).
+			Do(h).
+			ExpectStatus(http.StatusUnauthorized)
+	})
+
+	t.Run("admins transfer ownership", func(t *testing.T) {
+		testttp.
+			PostJSON(t, transferPath, body).
+			WrapCtx(authCtx(influxdb.OwnerPermissions(org.ID))).
+			Do(h).
+			ExpectStatus(http.StatusNoContent)
+
+		urms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceID:   edp.GetID(),
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		})
+		require.NoError(t, err)
+		require.Len(t, urms, 1)
+		assert.Equal(t, successor.ID, urms[0].UserID)
+		assert.Equal(t, influxdb.Owner, urms[0].UserType)
+	})
+
+	t.Run("the previous owner no longer owns the endpoint", func(t *testing.T) {
+		testttp.
+			PostJSON(t, transferPath, body).
+			WrapCtx(authCtx(influxdb.OwnerPermissions(org.ID))).
+			Do(h).
+			ExpectStatus(http.StatusNotFound)
+	})
+}
+
+func TestNotificationEndpointService_FindStream(t *testing.T) {
+	const total = 2*notificationEndpointStreamPageSize + 5
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		var page []string
+		for i := offset; i < total && i < offset+limit; i++ {
+			page = append(page, fmt.Sprintf(`{"id":"%016x","orgID":"020f755c3c082002","name":"edp-%d","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"}`, i+1, i))
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, `{"notificationEndpoints":[%s]}`, strings.Join(page, ","))
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "", false)
+	require.NoError(t, err)
+	svc := NewNotificationEndpointService(client)
+
+	it, err := svc.FindStream(context.Background(), influxdb.NotificationEndpointFilter{})
+	require.NoError(t, err)
+
+	var names []string
+	for it.Next() {
+		names = append(names, it.Endpoint().GetName())
+	}
+	require.NoError(t, it.Err())
+	require.Len(t, names, total)
+	assert.Equal(t, "edp-0", names[0])
+	assert.Equal(t, fmt.Sprintf("edp-%d", total-1), names[total-1])
+	assert.Equal(t, 3, requests)
+}
+
+func TestService_handleGetNotificationEndpoint_LabelProperties(t *testing.T) {
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:     influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+			OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082001"),
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	properties := map[string]string{
+		"color":       "#326BBA",
+		"description": "paged out of hours",
+		"team":        "storage",
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opts ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			return []influxdb.NotificationEndpoint{edp}, 1, nil
+		},
+	}
+	backend.LabelService = &mock.LabelService{
+		FindResourceLabelsFn: func(ctx context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+			return []*influxdb.Label{
+				{
+					ID:         influxTesting.MustIDBase16("fc3dc670a4be9b9a"),
+					Name:       "oncall",
+					Properties: properties,
+				},
+			}, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	type labelsResp struct {
+		Labels []influxdb.Label `json:"labels"`
+	}
+
+	t.Run("get", func(t *testing.T) {
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp labelsResp
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				require.Len(t, resp.Labels, 1)
+				assert.Equal(t, properties, resp.Labels[0].Properties)
+			})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					NotificationEndpoints []labelsResp `json:"notificationEndpoints"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				require.Len(t, resp.NotificationEndpoints, 1)
+				require.Len(t, resp.NotificationEndpoints[0].Labels, 1)
+				assert.Equal(t, properties, resp.NotificationEndpoints[0].Labels[0].Properties)
+			})
+	})
+}
+
+func TestService_handlePostNotificationEndpoint_AutoLabel(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	label := &influxdb.Label{OrgID: org.ID, Name: "managed-by:influx"}
+	require.NoError(t, svc.CreateLabel(ctx, label))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	WithAutoLabel(label.ID)(backend)
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	var id influxdb.ID
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, map[string]interface{}{
+			"type":   endpoint.SlackType,
+			"name":   "slack",
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/x/y/z",
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				ID     influxdb.ID      `json:"id"`
+				Labels []influxdb.Label `json:"labels"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			id = resp.ID
+			require.Len(t, resp.Labels, 1)
+			assert.Equal(t, label.ID, resp.Labels[0].ID)
+		})
+
+	labels, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+		ResourceID:   id,
+		ResourceType: influxdb.NotificationEndpointResourceType,
+	})
+	require.NoError(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, "managed-by:influx", labels[0].Name)
+}
+
+func TestNotificationEndpointService_Ping(t *testing.T) {
+	const token = "t0k3n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefixNotificationEndpoints || r.URL.Query().Get("limit") != "1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.Header.Get("Authorization") != "Token "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"code":"unauthorized","message":"unauthorized access"}`)
+			return
+		}
+		fmt.Fprint(w, `{"notificationEndpoints":[]}`)
+	}))
+	defer srv.Close()
+
+	t.Run("healthy", func(t *testing.T) {
+		client, err := NewHTTPClient(srv.URL, token, false)
+		require.NoError(t, err)
+		require.NoError(t, NewNotificationEndpointService(client).Ping(context.Background()))
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		client, err := NewHTTPClient(srv.URL, "wrong", false)
+		require.NoError(t, err)
+		err = NewNotificationEndpointService(client).Ping(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EUnauthorized, influxdb.ErrorCode(err))
+		assert.Contains(t, err.Error(), "notification endpoint service is unavailable")
+	})
+}
+
+func TestService_handlePostNotificationEndpoint_Fallback(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	otherOrg := &influxdb.Organization{Name: "other"}
+	require.NoError(t, svc.CreateOrganization(ctx, otherOrg))
+
+	newSlack := func(orgID *influxdb.ID, name string) *endpoint.Slack {
+		return &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  orgID,
+				Name:   name,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x/y/z",
+		}
+	}
+	fallback := newSlack(&org.ID, "fallback")
+	require.NoError(t, svc.CreateNotificationEndpoint(ctx, fallback, user1ID))
+	foreign := newSlack(&otherOrg.ID, "foreign")
+	require.NoError(t, svc.CreateNotificationEndpoint(ctx, foreign, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newBody := func(name string, fallbackID influxdb.ID) map[string]interface{} {
+		return map[string]interface{}{
+			"type":               endpoint.SlackType,
+			"name":               name,
+			"orgID":              org.ID.String(),
+			"status":             "active",
+			"url":                "https://hooks.slack.com/services/x/y/z",
+			"fallbackEndpointID": fallbackID.String(),
+		}
+	}
+
+	t.Run("fallback in the same org", func(t *testing.T) {
+		var id influxdb.ID
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("primary", fallback.GetID())).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					ID influxdb.ID `json:"id"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				id = resp.ID
+			})
+
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, id.String())).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					FallbackEndpointID influxdb.ID `json:"fallbackEndpointID"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Equal(t, fallback.GetID(), resp.FallbackEndpointID)
+			})
+	})
+
+	t.Run("fallback in another org is rejected", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("cross-org", foreign.GetID())).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest).
+			ExpectBody(func(body *bytes.Buffer) {
+				assert.Contains(t, body.String(), "belongs to another org")
+			})
+	})
+
+	t.Run("missing fallback is rejected", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, newBody("missing", influxTesting.MustIDBase16("020f755c3c0820ff"))).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handleGetNotificationEndpointsExport_TarGz(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	require.NoError(t, backend.NotificationEndpointService.CreateNotificationEndpoint(ctx, &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "ops",
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("s3cr3t")},
+		Channels: []string{"#alerts"},
+	}, user1ID))
+	require.NoError(t, backend.NotificationEndpointService.CreateNotificationEndpoint(ctx, &endpoint.HTTP{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "web/hook",
+			Status: influxdb.Active,
+		},
+		URL:        "https://example.com/hook",
+		Method:     "POST",
+		AuthMethod: "none",
+	}, user1ID))
+
+	testttp.
+		Get(t, notificationEndpointsExportPath+"?format=targz&orgID="+org.ID.String()).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("Content-Type", "application/gzip").
+		ExpectHeader("Content-Disposition", `attachment; filename="notification-endpoints-`+org.ID.String()+`.tar.gz"`).
+		ExpectBody(func(body *bytes.Buffer) {
+			gz, err := gzip.NewReader(body)
+			require.NoError(t, err)
+			tr := tar.NewReader(gz)
+
+			var names []string
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				names = append(names, hdr.Name)
+
+				b, err := ioutil.ReadAll(tr)
+				require.NoError(t, err)
+				assert.NotContains(t, string(b), "s3cr3t")
+			}
+			sort.Strings(names)
+			assert.Equal(t, []string{"http-web_hook.json", "slack-ops.json"}, names)
+		})
+
+	testttp.
+		Get(t, notificationEndpointsExportPath+"?format=zip&orgID="+org.ID.String()).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestService_handleGetNotificationEndpointsExport_WithRules(t *testing.T) {
+	orgID := influxTesting.MustIDBase16("020f755c3c082001")
+	slackID := influxTesting.MustIDBase16("020f755c3c082002")
+	pagerDutyID := influxTesting.MustIDBase16("020f755c3c082003")
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			return []influxdb.NotificationEndpoint{
+				&endpoint.Slack{
+					Base: endpoint.Base{ID: &slackID, OrgID: &orgID, Name: "ops", Status: influxdb.Active},
+					URL:  "https://hooks.slack.com/services/x/y/z",
+				},
+				&endpoint.PagerDuty{
+					Base:       endpoint.Base{ID: &pagerDutyID, OrgID: &orgID, Name: "oncall", Status: influxdb.Active},
+					ClientURL:  "https://events.pagerduty.com",
+					RoutingKey: influxdb.SecretField{Key: pagerDutyID.String() + "-routing-key"},
+				},
+			}, 2, nil
+		},
+	}
+	ruleStore := mock.NewNotificationRuleStore()
+	ruleStore.FindNotificationRulesF = func(ctx context.Context, filter influxdb.NotificationRuleFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+		return []influxdb.NotificationRule{
+			&rule.Slack{
+				Base: rule.Base{
+					ID:         influxTesting.MustIDBase16("020f755c3c082010"),
+					Name:       "cpu to slack",
+					EndpointID: slackID,
+					OrgID:      orgID,
+					TaskID:     influxTesting.MustIDBase16("020f755c3c082020"),
+				},
+				Channel: "#alerts",
+			},
+			&rule.PagerDuty{
+				Base: rule.Base{
+					ID:         influxTesting.MustIDBase16("020f755c3c082011"),
+					Name:       "disk to pagerduty",
+					EndpointID: pagerDutyID,
+					OrgID:      orgID,
+				},
+			},
+			&rule.Slack{
+				Base: rule.Base{
+					ID:         influxTesting.MustIDBase16("020f755c3c082012"),
+					Name:       "elsewhere",
+					EndpointID: influxTesting.MustIDBase16("020f755c3c082099"),
+					OrgID:      orgID,
+				},
+			},
+		}, 3, nil
+	}
+	backend.NotificationRuleStore = ruleStore
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	type bundle struct {
+		NotificationEndpoints []json.RawMessage `json:"notificationEndpoints"`
+		NotificationRules     []struct {
+			EndpointName string `json:"endpointName"`
+			Rule         struct {
+				Name   string      `json:"name"`
+				Type   string      `json:"type"`
+				TaskID influxdb.ID `json:"taskID"`
+			} `json:"rule"`
+		} `json:"notificationRules"`
+	}
+
+	testttp.
+		Get(t, notificationEndpointsExportPath+"?withRules=true&orgID="+orgID.String()).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var got bundle
+			require.NoError(t, json.Unmarshal(body.Bytes(), &got))
+			assert.Len(t, got.NotificationEndpoints, 2)
+			require.Len(t, got.NotificationRules, 2)
+
+			assert.Equal(t, "ops", got.NotificationRules[0].EndpointName)
+			assert.Equal(t, "cpu to slack", got.NotificationRules[0].Rule.Name)
+			assert.Equal(t, "slack", got.NotificationRules[0].Rule.Type)
+			assert.False(t, got.NotificationRules[0].Rule.TaskID.Valid())
+
+			assert.Equal(t, "oncall", got.NotificationRules[1].EndpointName)
+			assert.Equal(t, "disk to pagerduty", got.NotificationRules[1].Rule.Name)
+			assert.Equal(t, "pagerduty", got.NotificationRules[1].Rule.Type)
+		})
+
+	testttp.
+		Get(t, notificationEndpointsExportPath+"?orgID="+orgID.String()).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var got bundle
+			require.NoError(t, json.Unmarshal(body.Bytes(), &got))
+			assert.Len(t, got.NotificationEndpoints, 2)
+			assert.Empty(t, got.NotificationRules)
+		})
+
+	testttp.
+		Get(t, notificationEndpointsExportPath+"?withRules=true&format=targz&orgID="+orgID.String()).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestService_handleGetNotificationEndpoint_Include(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	member := &influxdb.User{Name: "member"}
+	require.NoError(t, svc.CreateUser(ctx, member))
+
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, svc.CreateNotificationEndpoint(ctx, edp, user1ID))
+	require.NoError(t, svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceID:   edp.GetID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+		UserID:       member.ID,
+		UserType:     influxdb.Member,
+	}))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = svc
+	backend.LabelService = svc
+	backend.UserService = svc
+	backend.UserResourceMappingService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	edpPath := path.Join(prefixNotificationEndpoints, edp.GetID().String())
+
+	t.Run("members are embedded when included", func(t *testing.T) {
+		testttp.
+			Get(t, edpPath+"?include=members").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Contains(t, resp, "labels")
+				assert.NotContains(t, resp, "owners")
+
+				var members []struct {
+					ID   influxdb.ID       `json:"id"`
+					Name string            `json:"name"`
+					Role influxdb.UserType `json:"role"`
+				}
+				require.NoError(t, json.Unmarshal(resp["members"], &members))
+				require.Len(t, members, 1)
+				assert.Equal(t, member.ID, members[0].ID)
+				assert.Equal(t, "member", members[0].Name)
+				assert.Equal(t, influxdb.Member, members[0].Role)
+			})
+	})
+
+	t.Run("members and owners are omitted by default", func(t *testing.T) {
+		testttp.
+			Get(t, edpPath).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.NotContains(t, resp, "members")
+				assert.NotContains(t, resp, "owners")
+			})
+	})
+
+	t.Run("unsupported include is rejected", func(t *testing.T) {
+		testttp.
+			Get(t, edpPath+"?include=secrets").
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestService_handleDeleteNotificationEndpoint_DryRun(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	label := &influxdb.Label{OrgID: org.ID, Name: "prod"}
+	require.NoError(t, svc.CreateLabel(ctx, label))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Value: strPtr("s3cr3t")},
+		Channels: []string{"#alerts"},
+	}
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+	require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+		LabelID:      label.ID,
+		ResourceID:   edp.GetID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+	}))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	backend.UserResourceMappingService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	edpPath := path.Join(prefixNotificationEndpoints, edp.GetID().String())
+	asOwner := func(ctx context.Context) context.Context {
+		return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+			UserID:      user1ID,
+			Status:      influxdb.Active,
+			Permissions: influxdb.OwnerPermissions(org.ID),
+		})
+	}
+
+	t.Run("requires write access", func(t *testing.T) {
+		testttp.
+			Delete(t, edpPath+"?dryRun=true").
+			WrapCtx(func(ctx context.Context) context.Context {
+				return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+					UserID:      user1ID,
+					Status:      influxdb.Active,
+					Permissions: influxdb.MemberPermissions(org.ID),
+				})
+			}).
+			Do(h).
+			ExpectStatus(http.StatusUnauthorized)
+	})
+
+	t.Run("lists the cascading deletions", func(t *testing.T) {
+		testttp.
+			Delete(t, edpPath+"?dryRun=true").
+			WrapCtx(asOwner).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var plan notificationEndpointDeletePlan
+				require.NoError(t, json.Unmarshal(body.Bytes(), &plan))
+				assert.Equal(t, edp.GetID(), plan.NotificationEndpointID)
+				require.Len(t, plan.LabelMappings, 1)
+				assert.Equal(t, label.ID, plan.LabelMappings[0].LabelID)
+				assert.Equal(t, []string{edp.GetID().String() + "-token"}, plan.SecretKeys)
+				require.Len(t, plan.UserResourceMappings, 1)
+				assert.Equal(t, user1ID, plan.UserResourceMappings[0].UserID)
+			})
+
+		_, err := svc.FindNotificationEndpointByID(ctx, edp.GetID())
+		require.NoError(t, err)
+		_, err = svc.LoadSecret(ctx, org.ID, edp.GetID().String()+"-token")
+		require.NoError(t, err)
+	})
+
+	t.Run("keeping secrets leaves them out of the plan", func(t *testing.T) {
+		testttp.
+			Delete(t, edpPath+"?dryRun=true&keepSecrets=true").
+			WrapCtx(asOwner).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var plan notificationEndpointDeletePlan
+				require.NoError(t, json.Unmarshal(body.Bytes(), &plan))
+				assert.Empty(t, plan.SecretKeys)
+			})
+	})
+
+	t.Run("delete removes the planned label mappings", func(t *testing.T) {
+		testttp.
+			Delete(t, edpPath).
+			WrapCtx(asOwner).
+			Do(h).
+			ExpectStatus(http.StatusNoContent)
+
+		labels, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
+		require.NoError(t, err)
+		assert.Empty(t, labels)
+	})
+}
+
+func TestService_handlePostNotificationEndpointsImport_Strategy(t *testing.T) {
+	const bundle = `{"strategy":%q,"notificationEndpoints":[{"type":"slack","name":"slack","status":"inactive","url":"https://hooks.slack.com/services/new","token":""}]}`
+
+	setup := func(t *testing.T) (*kv.Service, *influxdb.Organization, *endpoint.Slack, http.Handler) {
+		ctx := context.Background()
+		svc := newInMemKVSVC(t)
+
+		org := &influxdb.Organization{Name: "org"}
+		require.NoError(t, svc.CreateOrganization(ctx, org))
+
+		endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+		current := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   "slack",
+				Status: influxdb.Active,
+			},
+			URL:   "https://hooks.slack.com/services/old",
+			Token: influxdb.SecretField{Value: strPtr("s3cr3t")},
+		}
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, current, user1ID))
+
+		backend := NewMockNotificationEndpointBackend(t)
+		backend.NotificationEndpointService = endpointSVC
+		backend.LabelService = svc
+		return svc, org, current, NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+	}
+
+	findAll := func(t *testing.T, svc *kv.Service, orgID influxdb.ID) []influxdb.NotificationEndpoint {
+		edps, _, err := svc.FindNotificationEndpoints(context.Background(), influxdb.NotificationEndpointFilter{
+			OrgID: &orgID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		require.NoError(t, err)
+		return edps
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		svc, org, current, h := setup(t)
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?orgID="+org.ID.String(), strings.NewReader(fmt.Sprintf(bundle, "skip"))).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp importNotificationEndpointsResponse
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.Equal(t, []string{"slack"}, resp.Skipped)
+			})
+
+		edps := findAll(t, svc, org.ID)
+		require.Len(t, edps, 1)
+		assert.Equal(t, current.GetID(), edps[0].GetID())
+		assert.Equal(t, "https://hooks.slack.com/services/old", edps[0].(*endpoint.Slack).URL)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		svc, org, current, h := setup(t)
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?orgID="+org.ID.String(), strings.NewReader(fmt.Sprintf(bundle, "overwrite"))).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated)
+
+		edps := findAll(t, svc, org.ID)
+		require.Len(t, edps, 1)
+		assert.Equal(t, current.GetID(), edps[0].GetID())
+		assert.Equal(t, "https://hooks.slack.com/services/new", edps[0].(*endpoint.Slack).URL)
+		assert.Equal(t, influxdb.Inactive, edps[0].GetStatus())
+
+		// the placeholder token of the bundle keeps the existing secret.
+		secret, err := svc.LoadSecret(context.Background(), org.ID, current.GetID().String()+"-token")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", secret)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		svc, org, current, h := setup(t)
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?orgID="+org.ID.String(), strings.NewReader(fmt.Sprintf(bundle, "rename"))).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated)
+
+		edps := findAll(t, svc, org.ID)
+		require.Len(t, edps, 2)
+		names := map[string]influxdb.ID{}
+		for _, edp := range edps {
+			names[edp.GetName()] = edp.GetID()
+		}
+		assert.Equal(t, current.GetID(), names["slack"])
+		assert.Contains(t, names, "slack (imported 1)")
+	})
+
+	t.Run("unsupported strategy", func(t *testing.T) {
+		_, org, _, h := setup(t)
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?orgID="+org.ID.String(), strings.NewReader(fmt.Sprintf(bundle, "merge"))).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+	})
+}
+
+func TestNotificationEndpointService_UpdateNotificationEndpoint_Types(t *testing.T) {
+	var (
+		respType string
+		gotToken interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// echo the endpoint back, with the type overridden when set.
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		gotToken = body["token"]
+		if respType != "" {
+			body["type"] = respType
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "t0k3n", false)
+	require.NoError(t, err)
+	svc := NewNotificationEndpointService(client)
+
+	id := influxTesting.MustIDBase16("020f755c3c082000")
+	slack := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:     &id,
+			OrgID:  influxTesting.MustIDBase16Ptr("020f755c3c082001"),
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL:      "https://slack.com/api/chat.postMessage",
+		Token:    influxdb.SecretField{Key: id.String() + "-token"},
+		Channels: []string{"#alerts", "#oncall"},
+	}
+
+	t.Run("slack fields survive the round-trip", func(t *testing.T) {
+		respType = ""
+		updated, err := svc.UpdateNotificationEndpoint(context.Background(), id, slack, user1ID)
+		require.NoError(t, err)
+		got, ok := updated.(*endpoint.Slack)
+		require.True(t, ok, "expected a slack endpoint, got %T", updated)
+		assert.Equal(t, slack.URL, got.URL)
+		assert.Equal(t, slack.Channels, got.Channels)
+		assert.Equal(t, slack.Token.Key, got.Token.Key)
+		assert.Equal(t, "secret: "+slack.Token.Key, gotToken)
+	})
+
+	t.Run("new secret values are sent", func(t *testing.T) {
+		respType = ""
+		rotated := *slack
+		rotated.Token = influxdb.SecretField{Value: strPtr("n3w")}
+		_, err := svc.UpdateNotificationEndpoint(context.Background(), id, &rotated, user1ID)
+		require.NoError(t, err)
+		assert.Equal(t, "n3w", gotToken)
+	})
+
+	t.Run("unknown type is reported", func(t *testing.T) {
+		respType = "carrier-pigeon"
+		_, err := svc.UpdateNotificationEndpoint(context.Background(), id, slack, user1ID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid notification endpoint type carrier-pigeon")
+	})
+}
+
+func TestService_handleGetNotificationEndpoint_ZeroTimestamps(t *testing.T) {
+	createdAt := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:      influxTesting.MustIDBase16Ptr("020f755c3c082000"),
+			OrgID:   influxTesting.MustIDBase16Ptr("020f755c3c082001"),
+			Name:    "slack",
+			Status:  influxdb.Active,
+			CRUDLog: influxdb.CRUDLog{CreatedAt: createdAt},
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return edp, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	testttp.
+		Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			assert.JSONEq(t, `"2020-01-02T03:04:05Z"`, string(resp["createdAt"]))
+			assert.NotContains(t, resp, "updatedAt")
+			assert.NotContains(t, body.String(), "0001-01-01")
+		})
+}
+
+func TestService_handleGetNotificationEndpoint_JSONAPI(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	label := &influxdb.Label{OrgID: org.ID, Name: "prod"}
+	require.NoError(t, svc.CreateLabel(ctx, label))
+
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, svc.CreateNotificationEndpoint(ctx, edp, user1ID))
+	require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+		LabelID:      label.ID,
+		ResourceID:   edp.GetID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+	}))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = svc
+	backend.LabelService = svc
+	backend.UserService = svc
+	backend.UserResourceMappingService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	type resource struct {
+		Type          string                     `json:"type"`
+		ID            influxdb.ID                `json:"id"`
+		Attributes    map[string]json.RawMessage `json:"attributes"`
+		Relationships map[string]struct {
+			Data *[]struct {
+				Type string      `json:"type"`
+				ID   influxdb.ID `json:"id"`
+			} `json:"data"`
+			Links map[string]string `json:"links"`
+		} `json:"relationships"`
+		Links map[string]string `json:"links"`
+	}
+	assertResource := func(t *testing.T, res resource) {
+		t.Helper()
+		assert.Equal(t, "notificationEndpoints", res.Type)
+		assert.Equal(t, edp.GetID(), res.ID)
+		assert.JSONEq(t, `"slack"`, string(res.Attributes["name"]))
+		assert.JSONEq(t, `"slack"`, string(res.Attributes["endpointType"]))
+		assert.NotContains(t, res.Attributes, "id")
+		assert.NotContains(t, res.Attributes, "type")
+		assert.NotContains(t, res.Attributes, "labels")
+
+		labels := res.Relationships["labels"]
+		require.NotNil(t, labels.Data)
+		require.Len(t, *labels.Data, 1)
+		assert.Equal(t, "labels", (*labels.Data)[0].Type)
+		assert.Equal(t, label.ID, (*labels.Data)[0].ID)
+		assert.Equal(t, "/api/v2/notificationEndpoints/"+edp.GetID().String()+"/labels", labels.Links["related"])
+		assert.Nil(t, res.Relationships["members"].Data)
+		assert.Equal(t, "/api/v2/notificationEndpoints/"+edp.GetID().String(), res.Links["self"])
+	}
+
+	t.Run("get", func(t *testing.T) {
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())).
+			Headers("Accept", "application/vnd.api+json").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", "application/vnd.api+json").
+			ExpectBody(func(body *bytes.Buffer) {
+				var doc struct {
+					Data resource `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &doc))
+				assertResource(t, doc.Data)
+			})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID="+org.ID.String()).
+			Headers("Accept", "application/json;q=0.5, application/vnd.api+json").
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", "application/vnd.api+json").
+			ExpectBody(func(body *bytes.Buffer) {
+				var doc struct {
+					Data  []resource           `json:"data"`
+					Links influxdb.PagingLinks `json:"links"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &doc))
+				require.Len(t, doc.Data, 1)
+				assertResource(t, doc.Data[0])
+				assert.NotEmpty(t, doc.Links.Self)
+			})
+	})
+
+	t.Run("default format is unchanged", func(t *testing.T) {
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", "application/json; charset=utf-8").
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				assert.NotContains(t, resp, "data")
+				assert.JSONEq(t, `"slack"`, string(resp["type"]))
+			})
+	})
+}
+
+func TestNotificationEndpointService_WithUserAgent(t *testing.T) {
+	var userAgent, reqPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		reqPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, `{"id":"020f755c3c082000","orgID":"020f755c3c082002","name":"slack","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "", false)
+	require.NoError(t, err)
+	id := influxTesting.MustIDBase16("020f755c3c082000")
+
+	t.Run("user agent is sent", func(t *testing.T) {
+		s := NewNotificationEndpointService(client, WithUserAgent("alert-router/1.2"))
+		_, err := s.FindNotificationEndpointByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, "alert-router/1.2", userAgent)
+		assert.Equal(t, "/api/v2/notificationEndpoints/020f755c3c082000", reqPath)
+	})
+
+	t.Run("go default without the option", func(t *testing.T) {
+		s := NewNotificationEndpointService(client)
+		_, err := s.FindNotificationEndpointByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.NotEqual(t, "alert-router/1.2", userAgent)
+		assert.True(t, strings.HasPrefix(userAgent, "Go-http-client/"), userAgent)
+	})
+}
+
+func TestService_handleGetNotificationEndpoint_LastValidatedAt(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	require.NoError(t, svc.CreateNotificationEndpoint(ctx, edp, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = svc
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	getLastValidatedAt := func(t *testing.T) *time.Time {
+		t.Helper()
+		var resp struct {
+			LastValidatedAt *time.Time `json:"lastValidatedAt"`
+		}
+		testttp.
+			Get(t, path.Join(prefixNotificationEndpoints, edp.GetID().String())).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			})
+		return resp.LastValidatedAt
+	}
+
+	assert.Nil(t, getLastValidatedAt(t))
+
+	validatedAt := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	_, err := svc.RecordValidation(ctx, edp.GetID(), validatedAt, true)
+	require.NoError(t, err)
+	got := getLastValidatedAt(t)
+	require.NotNil(t, got)
+	assert.True(t, validatedAt.Equal(*got))
+
+	// the timestamp is read only, updates keep it.
+	edp.SetDescription("updated")
+	endpoint.SetLastValidatedAt(edp, nil)
+	_, err = svc.UpdateNotificationEndpoint(ctx, edp.GetID(), edp, user1ID)
+	require.NoError(t, err)
+	got = getLastValidatedAt(t)
+	require.NotNil(t, got)
+	assert.True(t, validatedAt.Equal(*got))
+
+	_, err = svc.RecordValidation(ctx, edp.GetID(), validatedAt.Add(time.Minute), false)
+	require.NoError(t, err)
+	assert.Nil(t, getLastValidatedAt(t))
+}
+
+func TestService_handlePostNotificationEndpointPreview_DedupKeyTemplate(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	var id influxdb.ID
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, map[string]interface{}{
+			"type":             endpoint.PagerDutyType,
+			"name":             "pager",
+			"orgID":            org.ID.String(),
+			"status":           "active",
+			"clientURL":        "https://example.com",
+			"routingKey":       "rk1",
+			"dedupKeyTemplate": "{{.host}}-{{._check_id}}",
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				ID influxdb.ID `json:"id"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			id = resp.ID
+		})
+
+	testttp.
+		PostJSON(t, strings.Replace(notificationEndpointsIDPreviewPath, ":id", id.String(), 1), map[string]interface{}{
+			"alert": map[string]interface{}{
+				"_message":  "cpu is high",
+				"_check_id": "0000000000000001",
+				"host":      "server01",
+			},
+		}).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var preview endpoint.Preview
+			require.NoError(t, json.Unmarshal(body.Bytes(), &preview))
+			var event map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(preview.Body), &event))
+			assert.Equal(t, "server01-0000000000000001", event["dedup_key"])
+		})
+}
+
+func TestService_handleGetNotificationEndpoints_Group(t *testing.T) {
+	var got influxdb.NotificationEndpointFilter
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			got = filter
+			return []influxdb.NotificationEndpoint{}, 0, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	testttp.
+		Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&group=020f755c3c082002").
+		Do(h).
+		ExpectStatus(http.StatusOK)
+	require.NotNil(t, got.GroupID)
+	assert.Equal(t, influxTesting.MustIDBase16("020f755c3c082002"), *got.GroupID)
+
+	testttp.
+		Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&group=bad").
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestService_handleGetNotificationEndpoints_IDsOnly(t *testing.T) {
+	orgID := influxTesting.MustIDBase16Ptr("020f755c3c082001")
+	ids := []influxdb.ID{
+		influxTesting.MustIDBase16("020f755c3c082002"),
+		influxTesting.MustIDBase16("020f755c3c082003"),
+	}
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointsF: func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			edps := make([]influxdb.NotificationEndpoint, 0, len(ids))
+			for i := range ids {
+				edps = append(edps, &endpoint.Slack{
+					Base: endpoint.Base{ID: &ids[i], OrgID: orgID, Name: ids[i].String()},
+				})
+			}
+			return edps, len(edps), nil
+		},
+	}
+	labelSVC := mock.NewLabelService()
+	backend.LabelService = labelSVC
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	testttp.
+		Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&idsOnly=true").
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("X-Total-Count", "2").
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			require.Len(t, resp, 1)
+			var got []influxdb.ID
+			require.NoError(t, json.Unmarshal(resp["ids"], &got))
+			assert.Equal(t, ids, got)
+		})
+	assert.Zero(t, labelSVC.FindResourceLabelsCalls.Count())
+
+	testttp.
+		Get(t, prefixNotificationEndpoints+"?orgID=020f755c3c082001&idsOnly=maybe").
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestService_handlePostNotificationEndpoint_MalformedBody(t *testing.T) {
+	backend := NewMockNotificationEndpointBackend(t)
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	// the type is known but the rest of the body doesn't decode into a slack endpoint
+	body := map[string]interface{}{
+		"type":   endpoint.SlackType,
+		"name":   1,
+		"orgID":  "020f755c3c082000",
+		"status": "active",
+	}
+	expectInvalid := func(body *bytes.Buffer) {
+		var resp struct {
+			Code string `json:"code"`
+		}
+		require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+		assert.Equal(t, influxdb.EInvalid, resp.Code)
+	}
+
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, body).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectBody(expectInvalid)
+
+	testttp.
+		PutJSON(t, prefixNotificationEndpoints+"/020f755c3c082001", body).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectBody(expectInvalid)
+}
+
+func TestService_handlePostNotificationEndpoint_Strict(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newBody := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":   endpoint.SlackType,
+			"name":   name,
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/a/b/c",
+			"labels": []string{},
+		}
+	}
+
+	t.Run("accepts a valid body", func(t *testing.T) {
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?strict=true", newBody("valid")).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated)
+	})
+
+	t.Run("rejects an unknown field", func(t *testing.T) {
+		body := newBody("unknown field")
+		body["chanel"] = "#alerts"
+
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?strict=true", body).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest).
+			ExpectBody(func(body *bytes.Buffer) {
+				assert.Contains(t, body.String(), `unknown field \"chanel\"`)
+			})
+
+		// without strict the unknown field is ignored.
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints, body).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusCreated)
+	})
+
+	t.Run("rejects a type mismatch", func(t *testing.T) {
+		body := newBody("type mismatch")
+		body["status"] = true
+
+		testttp.
+			PostJSON(t, prefixNotificationEndpoints+"?strict=true", body).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest).
+			ExpectBody(func(body *bytes.Buffer) {
+				assert.Contains(t, body.String(), `field \"status\" at offset`)
+			})
+	})
+}
+
+func TestService_handleGetNotificationEndpoints_Generation(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	backend.GenerationService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	asMember := func(ctx context.Context) context.Context {
+		return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+			UserID:      user1ID,
+			Status:      influxdb.Active,
+			Permissions: influxdb.MemberPermissions(org.ID),
+		})
+	}
+
+	generation := func(t *testing.T) string {
+		t.Helper()
+		resp := testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID="+org.ID.String()).
+			WrapCtx(asMember).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+		gen := resp.Rec.Header().Get("X-Endpoints-Generation")
+		require.NotEmpty(t, gen)
+		return gen
+	}
+
+	before := generation(t)
+
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, map[string]interface{}{
+			"type":   endpoint.SlackType,
+			"name":   "slack",
+			"orgID":  org.ID.String(),
+			"status": "active",
+			"url":    "https://hooks.slack.com/services/a/b/c",
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated)
+
+	after := generation(t)
+	assert.NotEqual(t, before, after)
+	assert.Equal(t, after, generation(t))
+
+	t.Run("requires org read access", func(t *testing.T) {
+		resp := testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID="+org.ID.String()).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusUnauthorized)
+		assert.Empty(t, resp.Rec.Header().Get("X-Endpoints-Generation"))
+	})
+}
+
+func TestService_handlePostNotificationEndpointsImport_ValidateOnly(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "taken",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/old",
+	}, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	findAll := func(t *testing.T) []influxdb.NotificationEndpoint {
+		edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &org.ID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		require.NoError(t, err)
+		return edps
+	}
+
+	const bundle = `{"notificationEndpoints":[
+		{"name":"fine","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"},
+		{"name":"taken","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"},
+		{"name":"no url","status":"active","type":"slack"}
+	]}`
+
+	testttp.
+		Post(t, notificationEndpointsImportPath+"?validateOnly=true&orgID="+org.ID.String(), strings.NewReader(bundle)).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp validateNotificationEndpointsImportResponse
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			assert.Equal(t, []notificationEndpointImportProblem{
+				{Index: 1, Name: "taken", Message: `notification endpoint "taken" already exists in the organization`},
+				{Index: 2, Name: "no url", Message: "slack endpoint URL must be provided"},
+			}, resp.Problems)
+		})
+
+	edps := findAll(t)
+	require.Len(t, edps, 1)
+	assert.Equal(t, "taken", edps[0].GetName())
+
+	t.Run("valid bundle", func(t *testing.T) {
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?validateOnly=true&orgID="+org.ID.String(), strings.NewReader(`{"notificationEndpoints":[
+				{"name":"fine","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"}
+			]}`)).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+
+		assert.Len(t, findAll(t), 1)
+	})
+
+	t.Run("import of a bundle with an invalid last endpoint creates nothing", func(t *testing.T) {
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?orgID="+org.ID.String(), strings.NewReader(`{"notificationEndpoints":[
+				{"name":"first","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"},
+				{"name":"second","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"},
+				{"name":"no url","status":"active","type":"slack"}
+			]}`)).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+
+		edps := findAll(t)
+		require.Len(t, edps, 1)
+		assert.Equal(t, "taken", edps[0].GetName())
+	})
+
+	t.Run("import of a bundle with an unknown secret store creates nothing", func(t *testing.T) {
+		backend := NewMockNotificationEndpointBackend(t)
+		backend.NotificationEndpointService = endpointSVC
+		backend.EndpointSecretService = endpointSVC
+		backend.LabelService = svc
+		h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+		testttp.
+			Post(t, notificationEndpointsImportPath+"?orgID="+org.ID.String(), strings.NewReader(`{"notificationEndpoints":[
+				{"name":"first","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z"},
+				{"name":"vault","status":"active","type":"slack","url":"https://hooks.slack.com/services/x/y/z","token":{"value":"t0k3n","store":"vault"}}
+			]}`)).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusBadRequest)
+
+		assert.Len(t, findAll(t), 1)
+	})
+}
+
+func TestService_handleGetNotificationEndpoints_ExcludeLabel(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	create := func(name string) influxdb.NotificationEndpoint {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   name,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x/y/z",
+		}
+		require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+		return edp
+	}
+	labeled := create("labeled")
+	create("unlabeled")
+
+	managed := &influxdb.Label{OrgID: org.ID, Name: "managed"}
+	require.NoError(t, svc.CreateLabel(ctx, managed))
+	require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+		LabelID:      managed.ID,
+		ResourceID:   labeled.GetID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+	}))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	names := func(t *testing.T, query string) []string {
+		t.Helper()
+		var got []string
+		testttp.
+			Get(t, prefixNotificationEndpoints+"?orgID="+org.ID.String()+query).
+			WrapCtx(authCtxFn(user1ID)).
+			Do(h).
+			ExpectStatus(http.StatusOK).
+			ExpectBody(func(body *bytes.Buffer) {
+				var resp struct {
+					NotificationEndpoints []struct {
+						Name string `json:"name"`
+					} `json:"notificationEndpoints"`
+				}
+				require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+				for _, edp := range resp.NotificationEndpoints {
+					got = append(got, edp.Name)
+				}
+			})
+		return got
+	}
+
+	assert.ElementsMatch(t, []string{"labeled", "unlabeled"}, names(t, ""))
+	assert.Equal(t, []string{"unlabeled"}, names(t, "&excludeLabel=managed"))
+	assert.ElementsMatch(t, []string{"labeled", "unlabeled"}, names(t, "&excludeLabel=unused"))
+}
+
+func TestService_handleNotificationEndpointHistory(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	endpointSVC := endpoints.NewService(svc, svc, svc, svc)
+	newSlack := func(url string) *endpoint.Slack {
+		return &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   "slack",
+				Status: influxdb.Active,
+			},
+			URL: url,
+		}
+	}
+	edp := newSlack("https://hooks.slack.com/services/v1")
+	require.NoError(t, endpointSVC.CreateNotificationEndpoint(ctx, edp, user1ID))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpointSVC
+	backend.HistoryService = svc
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	edpPath := path.Join(prefixNotificationEndpoints, edp.GetID().String())
+	asOwner := func(ctx context.Context) context.Context {
+		return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+			UserID:      user1ID,
+			Status:      influxdb.Active,
+			Permissions: influxdb.OwnerPermissions(org.ID),
+		})
+	}
+
+	for _, url := range []string{"https://hooks.slack.com/services/v2", "https://hooks.slack.com/services/v3"} {
+		upd := newSlack(url)
+		upd.ID = edp.ID
+		testttp.
+			PutJSON(t, edpPath, upd).
+			WrapCtx(asOwner).
+			Do(h).
+			ExpectStatus(http.StatusOK)
+	}
+
+	testttp.
+		Get(t, edpPath+"/history").
+		WrapCtx(asOwner).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				Versions []struct {
+					Version              int `json:"version"`
+					NotificationEndpoint struct {
+						URL string `json:"url"`
+					} `json:"notificationEndpoint"`
+				} `json:"versions"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			require.Len(t, resp.Versions, 2)
+			assert.Equal(t, 1, resp.Versions[0].Version)
+			assert.Equal(t, "https://hooks.slack.com/services/v1", resp.Versions[0].NotificationEndpoint.URL)
+			assert.Equal(t, "https://hooks.slack.com/services/v2", resp.Versions[1].NotificationEndpoint.URL)
+		})
+
+	t.Run("requires write access to restore", func(t *testing.T) {
+		testttp.
+			Post(t, edpPath+"/history/1/restore", nil).
+			WrapCtx(func(ctx context.Context) context.Context {
+				return pcontext.SetAuthorizer(ctx, &influxdb.Authorization{
+					UserID:      user1ID,
+					Status:      influxdb.Active,
+					Permissions: influxdb.MemberPermissions(org.ID),
+				})
+			}).
+			Do(h).
+			ExpectStatus(http.StatusUnauthorized)
+	})
+
+	testttp.
+		Post(t, edpPath+"/history/1/restore", nil).
+		WrapCtx(asOwner).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				URL string `json:"url"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			assert.Equal(t, "https://hooks.slack.com/services/v1", resp.URL)
+		})
+
+	got, err := svc.FindNotificationEndpointByID(ctx, edp.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/v1", got.(*endpoint.Slack).URL)
+
+	testttp.
+		Post(t, edpPath+"/history/0/restore", nil).
+		WrapCtx(asOwner).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestService_handlePostNotificationEndpointFromCurl(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	var id influxdb.ID
+	testttp.
+		PostJSON(t, notificationEndpointsFromCurlPath, map[string]interface{}{
+			"orgID": org.ID.String(),
+			"name":  "hook",
+			"curl":  `curl -X PUT -H "Authorization: Bearer t0k3n" -H 'Content-Type: application/json' -d '{"a":1}' https://example.com/hook`,
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				ID     influxdb.ID     `json:"id"`
+				Status influxdb.Status `json:"status"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			assert.Equal(t, influxdb.Active, resp.Status)
+			id = resp.ID
+		})
+
+	edp, err := svc.FindNotificationEndpointByID(ctx, id)
+	require.NoError(t, err)
+	got, ok := edp.(*endpoint.HTTP)
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com/hook", got.URL)
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, "bearer", got.AuthMethod)
+	assert.Equal(t, map[string]string{"content-type": "application/json"}, got.Headers)
+	assert.Equal(t, `{"a":1}`, got.ContentTemplate)
+
+	token, err := svc.LoadSecret(ctx, org.ID, got.Token.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "t0k3n", token)
+
+	testttp.
+		PostJSON(t, notificationEndpointsFromCurlPath, map[string]interface{}{
+			"orgID": org.ID.String(),
+			"name":  "bad",
+			"curl":  `wget https://example.com`,
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+
+	testttp.
+		PostJSON(t, notificationEndpointsFromCurlPath, map[string]interface{}{
+			"name": "no org",
+			"curl": `curl https://example.com`,
+		}).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestService_handlePostNotificationEndpoint_Discord(t *testing.T) {
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	backend := NewMockNotificationEndpointBackend(t)
+	backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+	backend.LabelService = svc
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+
+	newBody := func(name, url string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":      endpoint.DiscordType,
+			"name":      name,
+			"orgID":     org.ID.String(),
+			"status":    "active",
+			"url":       url,
+			"username":  "influxdb",
+			"avatarURL": "https://example.com/avatar.png",
+		}
+	}
+
+	var id influxdb.ID
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, newBody("discord", "https://discord.com/api/webhooks/123/abc")).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusCreated).
+		ExpectBody(func(body *bytes.Buffer) {
+			var resp struct {
+				ID   influxdb.ID `json:"id"`
+				Type string      `json:"type"`
+			}
+			require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+			assert.Equal(t, endpoint.DiscordType, resp.Type)
+			id = resp.ID
+		})
+
+	edp, err := svc.FindNotificationEndpointByID(ctx, id)
+	require.NoError(t, err)
+	got, ok := edp.(*endpoint.Discord)
+	require.True(t, ok, "expected a discord endpoint, got %T", edp)
+	assert.Equal(t, "https://discord.com/api/webhooks/123/abc", got.URL)
+	assert.Equal(t, "influxdb", got.Username)
+	assert.Equal(t, "https://example.com/avatar.png", got.AvatarURL)
+
+	testttp.
+		PostJSON(t, prefixNotificationEndpoints, newBody("insecure", "http://discord.com/api/webhooks/123/abc")).
+		WrapCtx(authCtxFn(user1ID)).
+		Do(h).
+		ExpectStatus(http.StatusBadRequest)
+}
+
+func TestNotificationEndpointEncoder_NoSecretFields(t *testing.T) {
+	edp := &endpoint.Discord{
+		Base: endpoint.Base{
+			Name:   "discord",
+			Status: influxdb.Active,
+		},
+		URL: "https://discord.com/api/webhooks/123/abc",
+	}
+	b, err := json.Marshal(&notificationEndpointEncoder{ne: edp})
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, endpoint.DiscordType, got["type"])
+	assert.Equal(t, edp.URL, got["url"])
+	assert.NotContains(t, got, "token")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/pkg/httpc"
 	"go.uber.org/zap"
@@ -26,6 +27,8 @@ type OrgBackend struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+
+	NotificationEndpointDefaultsService influxdb.NotificationEndpointDefaultsService
 }
 
 // NewOrgBackend is a datasource used by the org handler.
@@ -40,6 +43,8 @@ func NewOrgBackend(log *zap.Logger, b *APIBackend) *OrgBackend {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+
+		NotificationEndpointDefaultsService: b.NotificationEndpointDefaultsService,
 	}
 }
 
@@ -55,6 +60,8 @@ type OrgHandler struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+
+	NotificationEndpointDefaultsService influxdb.NotificationEndpointDefaultsService
 }
 
 const (
@@ -70,6 +77,8 @@ const (
 	organizationsIDSecretsDeletePath = "/api/v2/orgs/:id/secrets/delete"
 	organizationsIDLabelsPath        = "/api/v2/orgs/:id/labels"
 	organizationsIDLabelsIDPath      = "/api/v2/orgs/:id/labels/:lid"
+
+	organizationsIDNotificationEndpointDefaultsPath = "/api/v2/orgs/:id/notificationEndpointDefaults"
 )
 
 func checkOrganziationExists(handler *OrgHandler) Middleware {
@@ -107,6 +116,8 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+
+		NotificationEndpointDefaultsService: b.NotificationEndpointDefaultsService,
 	}
 
 	h.HandlerFunc("POST", prefixOrganizations, h.handlePostOrg)
@@ -145,6 +156,9 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 	// TODO(desa): need a way to specify which secrets to delete. this should work for now
 	h.HandlerFunc("POST", organizationsIDSecretsDeletePath, h.handleDeleteSecrets)
 
+	h.HandlerFunc("GET", organizationsIDNotificationEndpointDefaultsPath, h.handleGetNotificationEndpointDefaults)
+	h.HandlerFunc("PUT", organizationsIDNotificationEndpointDefaultsPath, h.handlePutNotificationEndpointDefaults)
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -591,6 +605,79 @@ func decodeDeleteSecretsRequest(ctx context.Context, r *http.Request) (*deleteSe
 	return req, nil
 }
 
+// handleGetNotificationEndpointDefaults is the HTTP handler for the GET /api/v2/orgs/:id/notificationEndpointDefaults route.
+func (h *OrgHandler) handleGetNotificationEndpointDefaults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetOrgRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := authorizeOrg(ctx, influxdb.ReadAction, req.OrgID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	d, err := h.NotificationEndpointDefaultsService.FindNotificationEndpointDefaults(ctx, req.OrgID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		// an org without defaults has empty defaults.
+		d, err = &influxdb.NotificationEndpointDefaults{OrgID: req.OrgID}, nil
+	}
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, d); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePutNotificationEndpointDefaults is the HTTP handler for the PUT /api/v2/orgs/:id/notificationEndpointDefaults route.
+func (h *OrgHandler) handlePutNotificationEndpointDefaults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetOrgRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := authorizeOrg(ctx, influxdb.WriteAction, req.OrgID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var d influxdb.NotificationEndpointDefaults
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}, w)
+		return
+	}
+	d.OrgID = req.OrgID
+
+	if err := h.NotificationEndpointDefaultsService.PutNotificationEndpointDefaults(ctx, &d); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Notification endpoint defaults replaced", zap.String("orgID", req.OrgID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, d); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// authorizeOrg returns an error if the action on the org is not allowed.
+func authorizeOrg(ctx context.Context, action influxdb.Action, orgID influxdb.ID) error {
+	p := influxdb.Permission{
+		Action:   action,
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &orgID},
+	}
+	return authorizer.IsAllowed(ctx, p)
+}
+
 const (
 	organizationPath = "/api/v2/orgs"
 )
@@ -9,11 +9,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/kv"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/pkg/testttp"
 	platformtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -378,3 +382,85 @@ func TestSecretService_handleDeleteSecrets(t *testing.T) {
 		})
 	}
 }
+
+func TestOrgHandler_NotificationEndpointDefaults(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &platform.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewMockOrgBackend(t)
+	backend.HTTPErrorHandler = ErrorHandler(0)
+	backend.NotificationEndpointDefaultsService = svc
+	h := NewOrgHandler(zaptest.NewLogger(t), backend)
+
+	defaultsPath := "/api/v2/orgs/" + org.ID.String() + "/notificationEndpointDefaults"
+	ownerCtx := func(ctx context.Context) context.Context {
+		return pcontext.SetAuthorizer(ctx, &platform.Authorization{
+			Status:      platform.Active,
+			OrgID:       org.ID,
+			Permissions: platform.OwnerPermissions(org.ID),
+		})
+	}
+	testttp.
+		PutJSON(t, defaultsPath, map[string]interface{}{
+			"timeout": "30s",
+			"retries": 3,
+		}).
+		WrapCtx(ownerCtx).
+		Do(h).
+		ExpectStatus(http.StatusOK)
+
+	inherited := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "inherited",
+			Status: platform.Active,
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, inherited, 1); err != nil {
+		t.Fatal(err)
+	}
+	if inherited.Timeout == nil || inherited.Timeout.Duration != 30*time.Second {
+		t.Errorf("expected the org default timeout of 30s, got %v", inherited.Timeout)
+	}
+	if inherited.Retries == nil || *inherited.Retries != 3 {
+		t.Errorf("expected the org default retries of 3, got %v", inherited.Retries)
+	}
+
+	overridden := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:   &org.ID,
+			Name:    "overridden",
+			Status:  platform.Active,
+			Timeout: &platform.Duration{Duration: 5 * time.Second},
+		},
+		URL: "https://hooks.slack.com/services/x/y/z",
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, overridden, 1); err != nil {
+		t.Fatal(err)
+	}
+	if overridden.Timeout.Duration != 5*time.Second {
+		t.Errorf("expected the endpoint timeout to be kept, got %v", overridden.Timeout)
+	}
+
+	t.Run("members can't replace the defaults", func(t *testing.T) {
+		testttp.
+			PutJSON(t, defaultsPath, map[string]interface{}{"retries": 1}).
+			WrapCtx(func(ctx context.Context) context.Context {
+				return pcontext.SetAuthorizer(ctx, &platform.Authorization{
+					Status:      platform.Active,
+					OrgID:       org.ID,
+					Permissions: platform.MemberPermissions(org.ID),
+				})
+			}).
+			Do(h).
+			ExpectStatus(http.StatusUnauthorized)
+	})
+}
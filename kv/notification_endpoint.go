@@ -2,6 +2,8 @@ package kv
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
@@ -66,15 +68,43 @@ func (s *Service) createNotificationEndpoint(ctx context.Context, tx Tx, edp inf
 	}
 
 	id := s.IDGenerator.ID()
+	if provided, ok := influxdb.ClientProvidedNotificationEndpointID(ctx); ok {
+		if err := s.validClientProvidedNotificationEndpointID(ctx, tx, provided); err != nil {
+			return err
+		}
+		id = provided
+	}
 	edp.SetID(id)
+	if edp.GetOrgID().Valid() {
+		d, err := s.findNotificationEndpointDefaults(ctx, tx, edp.GetOrgID())
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		if err := s.validNotificationEndpointLimit(ctx, tx, edp.GetOrgID(), d.MaxNotificationEndpoints()); err != nil {
+			return err
+		}
+		if d != nil {
+			endpoint.InheritDefaults(edp, *d)
+		}
+	}
 	now := s.TimeGenerator.Now()
 	edp.SetCreatedAt(now)
 	edp.SetUpdatedAt(now)
+	// the muted state is read only, it is only set by patching the endpoint.
+	edp.SetMutedUntil(nil)
+	endpoint.SetLastValidatedAt(edp, nil)
+	endpoint.ScheduleSecretRotation(edp, now)
 	edp.BackfillSecretKeys()
 
 	if err := edp.Valid(); err != nil {
 		return err
 	}
+	if err := s.validFallbackEndpoint(ctx, tx, edp); err != nil {
+		return err
+	}
+	if err := s.validNotificationEndpointGroup(ctx, tx, edp); err != nil {
+		return err
+	}
 
 	ent := Entity{
 		PK:        EncID(edp.GetID()),
@@ -84,6 +114,12 @@ func (s *Service) createNotificationEndpoint(ctx context.Context, tx Tx, edp inf
 	if err := s.endpointStore.Put(ctx, tx, ent, PutNew()); err != nil {
 		return err
 	}
+	if err := s.putNotificationEndpointNameIndex(tx, edp); err != nil {
+		return err
+	}
+	if err := s.bumpNotificationEndpointGeneration(tx, edp.GetOrgID()); err != nil {
+		return err
+	}
 
 	urm := &influxdb.UserResourceMapping{
 		ResourceID:   edp.GetID(),
@@ -94,6 +130,22 @@ func (s *Service) createNotificationEndpoint(ctx context.Context, tx Tx, edp inf
 	return s.createUserResourceMapping(ctx, tx, urm)
 }
 
+// validNotificationEndpointLimit returns an error if the org already has the maximum
+// number of notification endpoints.
+func (s *Service) validNotificationEndpointLimit(ctx context.Context, tx Tx, orgID influxdb.ID, max int) error {
+	ids, err := s.findNotificationEndpointIDsByNamePrefix(ctx, tx, orgID, "")
+	if err != nil {
+		return err
+	}
+	if len(ids) >= max {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("organization %s has reached its limit of %d notification endpoints", orgID, max),
+		}
+	}
+	return nil
+}
+
 // UpdateNotificationEndpoint updates a single notification endpoint.
 // Returns the new notification endpoint after update.
 func (s *Service) UpdateNotificationEndpoint(ctx context.Context, id influxdb.ID, edp influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
@@ -110,14 +162,24 @@ func (s *Service) updateNotificationEndpoint(ctx context.Context, tx Tx, id infl
 	if err != nil {
 		return nil, err
 	}
+	prevName := current.GetName()
 
 	// ID and OrganizationID can not be updated
 	edp.SetCreatedAt(current.GetCRUDLog().CreatedAt)
 	edp.SetUpdatedAt(s.TimeGenerator.Now())
+	edp.SetMutedUntil(current.GetMutedUntil())
+	endpoint.SetLastValidatedAt(edp, endpoint.LastValidatedAt(current))
+	endpoint.ScheduleSecretRotation(edp, edp.GetCRUDLog().UpdatedAt)
 
 	if err := edp.Valid(); err != nil {
 		return nil, err
 	}
+	if err := s.validFallbackEndpoint(ctx, tx, edp); err != nil {
+		return nil, err
+	}
+	if err := s.validNotificationEndpointGroup(ctx, tx, edp); err != nil {
+		return nil, err
+	}
 
 	ent := Entity{
 		PK:        EncID(edp.GetID()),
@@ -127,10 +189,104 @@ func (s *Service) updateNotificationEndpoint(ctx context.Context, tx Tx, id infl
 	if err := s.endpointStore.Put(ctx, tx, ent, PutUpdate()); err != nil {
 		return nil, err
 	}
+	if err := s.reindexNotificationEndpointName(ctx, tx, prevName, edp); err != nil {
+		return nil, err
+	}
+	if err := s.recordNotificationEndpointVersion(tx, current, edp.GetCRUDLog().UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := s.bumpNotificationEndpointGeneration(tx, edp.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	return edp, nil
+}
 
+// RecordValidation records the outcome of a connectivity check of the notification
+// endpoint. A successful check sets its last validated time to t, a failed one clears it.
+func (s *Service) RecordValidation(ctx context.Context, id influxdb.ID, t time.Time, ok bool) (influxdb.NotificationEndpoint, error) {
+	var edp influxdb.NotificationEndpoint
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		edp, err = s.findNotificationEndpointByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		var validatedAt *time.Time
+		if ok {
+			t = t.UTC()
+			validatedAt = &t
+		}
+		endpoint.SetLastValidatedAt(edp, validatedAt)
+
+		ent := Entity{
+			PK:        EncID(edp.GetID()),
+			UniqueKey: Encode(EncID(edp.GetOrgID()), EncString(edp.GetName())),
+			Body:      edp,
+		}
+		if err := s.endpointStore.Put(ctx, tx, ent, PutUpdate()); err != nil {
+			return err
+		}
+		return s.bumpNotificationEndpointGeneration(tx, edp.GetOrgID())
+	})
+	if err != nil {
+		return nil, err
+	}
 	return edp, nil
 }
 
+// FindNotificationEndpointsDueForSecretRotation returns the notification endpoints whose
+// secrets are due for rotation at now according to their rotation policy.
+func (s *Service) FindNotificationEndpointsDueForSecretRotation(ctx context.Context, now time.Time) ([]influxdb.NotificationEndpoint, error) {
+	edps := []influxdb.NotificationEndpoint{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.endpointStore.Find(ctx, tx, FindOpts{
+			CaptureFn: func(_ []byte, v interface{}) error {
+				edp, ok := v.(influxdb.NotificationEndpoint)
+				if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+					return err
+				}
+				policy, ok := endpoint.SecretRotation(edp)
+				if ok && policy.NextRotationAt != nil && !policy.NextRotationAt.After(now) {
+					edps = append(edps, edp)
+				}
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edps, nil
+}
+
+// validFallbackEndpoint returns an error if the fallback of the notification endpoint
+// does not exist or belongs to another org.
+func (s *Service) validFallbackEndpoint(ctx context.Context, tx Tx, edp influxdb.NotificationEndpoint) error {
+	fallbackID, ok := endpoint.FallbackEndpointID(edp)
+	if !ok {
+		return nil
+	}
+	fallback, err := s.findNotificationEndpointByID(ctx, tx, fallbackID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("fallback notification endpoint %s does not exist", fallbackID),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if fallback.GetOrgID() != edp.GetOrgID() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("fallback notification endpoint %s belongs to another org", fallbackID),
+		}
+	}
+	return nil
+}
+
 // PatchNotificationEndpoint updates a single  notification endpoint with changeset.
 // Returns the new notification endpoint state after update.
 func (s *Service) PatchNotificationEndpoint(ctx context.Context, id influxdb.ID, upd influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
@@ -153,6 +309,7 @@ func (s *Service) patchNotificationEndpoint(ctx context.Context, tx Tx, id influ
 	if err != nil {
 		return nil, err
 	}
+	prevName := edp.GetName()
 
 	if upd.Name != nil {
 		edp.SetName(*upd.Name)
@@ -163,6 +320,18 @@ func (s *Service) patchNotificationEndpoint(ctx context.Context, tx Tx, id influ
 	if upd.Status != nil {
 		edp.SetStatus(*upd.Status)
 	}
+	if upd.Priority != nil {
+		edp.SetPriority(*upd.Priority)
+	}
+	if upd.DeleteProtected != nil {
+		endpoint.SetDeleteProtected(edp, *upd.DeleteProtected)
+	}
+	if upd.MutedUntil != nil {
+		edp.SetMutedUntil(upd.MutedUntil)
+	}
+	if upd.Unmute {
+		edp.SetMutedUntil(nil)
+	}
 	edp.SetUpdatedAt(s.TimeGenerator.Now())
 
 	if err := edp.Valid(); err != nil {
@@ -179,6 +348,12 @@ func (s *Service) patchNotificationEndpoint(ctx context.Context, tx Tx, id influ
 	if err := s.endpointStore.Put(ctx, tx, ent, PutUpdate()); err != nil {
 		return nil, err
 	}
+	if err := s.reindexNotificationEndpointName(ctx, tx, prevName, edp); err != nil {
+		return nil, err
+	}
+	if err := s.bumpNotificationEndpointGeneration(tx, edp.GetOrgID()); err != nil {
+		return nil, err
+	}
 
 	return edp, nil
 }
@@ -197,7 +372,27 @@ func (s *Service) PutNotificationEndpoint(ctx context.Context, edp influxdb.Noti
 			UniqueKey: Encode(EncID(edp.GetOrgID()), EncString(edp.GetName())),
 			Body:      edp,
 		}
-		return s.endpointStore.Put(ctx, tx, ent)
+		current, err := s.findNotificationEndpointByID(ctx, tx, edp.GetID())
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		if err := s.endpointStore.Put(ctx, tx, ent); err != nil {
+			return err
+		}
+		if current == nil {
+			err = s.putNotificationEndpointNameIndex(tx, edp)
+		} else {
+			err = s.reindexNotificationEndpointName(ctx, tx, current.GetName(), edp)
+		}
+		if err != nil {
+			return err
+		}
+		if current != nil && current.GetOrgID() != edp.GetOrgID() {
+			if err := s.bumpNotificationEndpointGeneration(tx, current.GetOrgID()); err != nil {
+				return err
+			}
+		}
+		return s.bumpNotificationEndpointGeneration(tx, edp.GetOrgID())
 	})
 }
 
@@ -216,16 +411,49 @@ func (s *Service) FindNotificationEndpointByID(ctx context.Context, id influxdb.
 	return edp, err
 }
 
+// validClientProvidedNotificationEndpointID returns an error if the client provided ID
+// is malformed or already taken by another notification endpoint.
+func (s *Service) validClientProvidedNotificationEndpointID(ctx context.Context, tx Tx, id influxdb.ID) error {
+	if !id.Valid() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "provided notification endpoint ID is invalid",
+		}
+	}
+	_, err := s.findNotificationEndpointByID(ctx, tx, id)
+	if err == nil {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("notification endpoint with ID %s already exists", id),
+		}
+	}
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
 func (s *Service) findNotificationEndpointByID(ctx context.Context, tx Tx, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
 	decodedEnt, err := s.endpointStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
 	if err != nil {
 		return nil, err
 	}
 	edp, ok := decodedEnt.(influxdb.NotificationEndpoint)
-	return edp, IsErrUnexpectedDecodeVal(ok)
+	if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+		return nil, err
+	}
+	s.clearExpiredMute(edp)
+	return edp, nil
 }
 
-// FindNotificationEndpoints returns a list of notification endpoints that match isNext and the total count of matching notification endpoints.
+// clearExpiredMute drops the muted state of an endpoint once it has passed.
+func (s *Service) clearExpiredMute(edp influxdb.NotificationEndpoint) {
+	if until := edp.GetMutedUntil(); until != nil && !until.After(s.TimeGenerator.Now()) {
+		edp.SetMutedUntil(nil)
+	}
+}
+
+// FindNotificationEndpoints returns a page of the notification endpoints that match the filter and the total count of matching notification endpoints.
 // Additional options provide pagination & sorting.
 func (s *Service) FindNotificationEndpoints(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) (edps []influxdb.NotificationEndpoint, n int, err error) {
 	err = s.kv.View(ctx, func(tx Tx) error {
@@ -250,6 +478,12 @@ func (s *Service) findNotificationEndpoints(ctx context.Context, tx Tx, filter i
 		idMap[item.ResourceID] = true
 	}
 
+	if filter.ExcludeLabel != nil {
+		if err := s.excludeLabeledResources(ctx, tx, idMap, *filter.ExcludeLabel); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	if filter.Org != nil {
 		o, err := s.findOrganizationByName(ctx, tx, *filter.Org)
 		if err != nil {
@@ -265,26 +499,67 @@ func (s *Service) findNotificationEndpoints(ctx context.Context, tx Tx, filter i
 		o = opt[0]
 	}
 
+	// sorting happens in memory so the store must hand back every match
+	// before the offset and limit are applied.
 	edps := make([]influxdb.NotificationEndpoint, 0)
-	err = s.endpointStore.Find(ctx, tx, FindOpts{
-		Descending:  o.Descending,
-		Offset:      o.Offset,
-		Limit:       o.Limit,
-		FilterEntFn: filterEndpointsFn(idMap, filter),
-		CaptureFn: func(k []byte, v interface{}) error {
-			edp, ok := v.(influxdb.NotificationEndpoint)
-			if err := IsErrUnexpectedDecodeVal(ok); err != nil {
-				return err
+	if filter.Name != nil && filter.OrgID != nil {
+		// the name index narrows the lookup down to the endpoints named alike, instead
+		// of a scan of every endpoint.
+		ids, err := s.findNotificationEndpointIDsByNamePrefix(ctx, tx, *filter.OrgID, *filter.Name)
+		if err != nil {
+			return nil, 0, err
+		}
+		keep := filterEndpointsFn(idMap, filter)
+		for _, id := range ids {
+			edp, err := s.findNotificationEndpointByID(ctx, tx, id)
+			if err != nil {
+				return nil, 0, err
 			}
-			edps = append(edps, edp)
-			return nil
-		},
-	})
-	if err != nil {
-		return nil, 0, err
+			if keep(nil, edp) {
+				edps = append(edps, edp)
+			}
+		}
+	} else {
+		err = s.endpointStore.Find(ctx, tx, FindOpts{
+			FilterEntFn: filterEndpointsFn(idMap, filter),
+			CaptureFn: func(k []byte, v interface{}) error {
+				edp, ok := v.(influxdb.NotificationEndpoint)
+				if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+					return err
+				}
+				s.clearExpiredMute(edp)
+				edps = append(edps, edp)
+				return nil
+			},
+		})
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 
-	return edps, len(edps), err
+	// the count is of every match, so clients can page through all of them.
+	n := len(edps)
+	influxdb.SortNotificationEndpoints(o, edps)
+	edps = influxdb.PageNotificationEndpoints(o, edps)
+
+	return edps, n, err
+}
+
+// excludeLabeledResources removes the resources with a label of the name from the ids.
+func (s *Service) excludeLabeledResources(ctx context.Context, tx Tx, ids map[influxdb.ID]bool, labelName string) error {
+	for id := range ids {
+		var labels []*influxdb.Label
+		if err := s.findResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: id}, &labels); err != nil {
+			return err
+		}
+		for _, l := range labels {
+			if l.Name == labelName {
+				delete(ids, id)
+				break
+			}
+		}
+	}
+	return nil
 }
 
 func filterEndpointsFn(idMap map[influxdb.ID]bool, filter influxdb.NotificationEndpointFilter) func([]byte, interface{}) bool {
@@ -298,6 +573,16 @@ func filterEndpointsFn(idMap map[influxdb.ID]bool, filter influxdb.NotificationE
 			return false
 		}
 
+		if filter.Name != nil && edp.GetName() != *filter.Name {
+			return false
+		}
+
+		if filter.GroupID != nil {
+			if groupID, ok := endpoint.GroupID(edp); !ok || groupID != *filter.GroupID {
+				return false
+			}
+		}
+
 		if idMap == nil {
 			return true
 		}
@@ -323,6 +608,37 @@ func (s *Service) deleteNotificationEndpoint(ctx context.Context, tx Tx, id infl
 	if err := s.endpointStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)}); err != nil {
 		return nil, 0, err
 	}
+	if err := s.removeNotificationEndpointNameIndex(tx, edp); err != nil {
+		return nil, 0, err
+	}
+	sendsPrefix, err := notificationEndpointSendsPrefix(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.deleteNotificationEndpointSends(tx, sendsPrefix, nil); err != nil {
+		return nil, 0, err
+	}
+	if err := s.deleteNotificationEndpointHistory(tx, id); err != nil {
+		return nil, 0, err
+	}
+	if err := s.bumpNotificationEndpointGeneration(tx, edp.GetOrgID()); err != nil {
+		return nil, 0, err
+	}
+
+	var labels []*influxdb.Label
+	if err := s.findResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: id}, &labels); err != nil {
+		return nil, 0, err
+	}
+	for _, l := range labels {
+		m := &influxdb.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   id,
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		}
+		if err := s.deleteLabelMapping(ctx, tx, m); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	return edp.SecretFields(), edp.GetOrgID(), s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
 		ResourceID:   id,
@@ -0,0 +1,142 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/endpoint"
+)
+
+// notificationEndpointSendsBucket holds a key per notification sent to an endpoint: the
+// endpoint id, the big endian unix nano send time and a unique id, so the sends of an
+// endpoint are ordered by time.
+var notificationEndpointSendsBucket = []byte("notificationEndpointSendsv1")
+
+// notificationBudgetWindow is the rolling window of the notification budget of endpoints.
+const notificationBudgetWindow = time.Hour
+
+func (s *Service) initializeNotificationEndpointSends(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(notificationEndpointSendsBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+func notificationEndpointSendsPrefix(id influxdb.ID) ([]byte, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad notification endpoint id",
+			Err:  err,
+		}
+	}
+	return key, nil
+}
+
+func notificationEndpointSendsKey(prefix []byte, t time.Time) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], uint64(t.UnixNano()))
+	return key
+}
+
+// RecordNotificationSent records a notification sent to the notification endpoint at t.
+// The sends that fell out of the budget window are dropped along the way.
+func (s *Service) RecordNotificationSent(ctx context.Context, id influxdb.ID, t time.Time) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findNotificationEndpointByID(ctx, tx, id); err != nil {
+			return err
+		}
+		prefix, err := notificationEndpointSendsPrefix(id)
+		if err != nil {
+			return err
+		}
+		if err := s.deleteNotificationEndpointSends(tx, prefix, notificationEndpointSendsKey(prefix, t.Add(-notificationBudgetWindow))); err != nil {
+			return err
+		}
+
+		sendID, err := s.IDGenerator.ID().Encode()
+		if err != nil {
+			return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+		}
+		b, err := tx.Bucket(notificationEndpointSendsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(append(notificationEndpointSendsKey(prefix, t), sendID...), nil)
+	})
+}
+
+// FindNotificationEndpointBudget returns how much of its notification budget the
+// notification endpoint used over the rolling hour ending at now.
+func (s *Service) FindNotificationEndpointBudget(ctx context.Context, id influxdb.ID, now time.Time) (influxdb.NotificationEndpointBudget, error) {
+	var budget influxdb.NotificationEndpointBudget
+	err := s.kv.View(ctx, func(tx Tx) error {
+		edp, err := s.findNotificationEndpointByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		budget.Max = endpoint.MaxNotificationsPerHour(edp)
+
+		prefix, err := notificationEndpointSendsPrefix(id)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(notificationEndpointSendsBucket)
+		if err != nil {
+			return err
+		}
+		// Cursor.Seek only finds keys starting with what it is given, the forward cursor
+		// starts at the first key at or after the window start instead.
+		from := notificationEndpointSendsKey(prefix, now.Add(-notificationBudgetWindow))
+		to := notificationEndpointSendsKey(prefix, now)
+		cur, err := b.ForwardCursor(from)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, _ := cur.Next(); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+			if bytes.Compare(k[:len(to)], to) > 0 {
+				break
+			}
+			budget.Sent++
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return influxdb.NotificationEndpointBudget{}, err
+	}
+	return budget, nil
+}
+
+// deleteNotificationEndpointSends deletes the sends of the endpoint with the key prefix
+// whose keys sort before until, or all of them when until is nil.
+func (s *Service) deleteNotificationEndpointSends(tx Tx, prefix, until []byte) error {
+	b, err := tx.Bucket(notificationEndpointSendsBucket)
+	if err != nil {
+		return err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		if until != nil && bytes.Compare(k, until) >= 0 {
+			break
+		}
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var notificationEndpointDefaultsBucket = []byte("notificationEndpointDefaultsv1")
+
+var _ influxdb.NotificationEndpointDefaultsService = (*Service)(nil)
+
+func (s *Service) initializeNotificationEndpointDefaults(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(notificationEndpointDefaultsBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindNotificationEndpointDefaults returns the notification endpoint defaults of the org.
+func (s *Service) FindNotificationEndpointDefaults(ctx context.Context, orgID influxdb.ID) (*influxdb.NotificationEndpointDefaults, error) {
+	var d *influxdb.NotificationEndpointDefaults
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		d, err = s.findNotificationEndpointDefaults(ctx, tx, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *Service) findNotificationEndpointDefaults(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.NotificationEndpointDefaults, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(notificationEndpointDefaultsBucket)
+	if err != nil {
+		return nil, err
+	}
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "notification endpoint defaults not found",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var d influxdb.NotificationEndpointDefaults
+	if err := json.Unmarshal(v, &d); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &d, nil
+}
+
+// PutNotificationEndpointDefaults replaces the notification endpoint defaults of the org.
+func (s *Service) PutNotificationEndpointDefaults(ctx context.Context, d *influxdb.NotificationEndpointDefaults) error {
+	if err := d.Valid(); err != nil {
+		return err
+	}
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, d.OrgID); err != nil {
+			return err
+		}
+
+		key, err := d.OrgID.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		v, err := json.Marshal(d)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(notificationEndpointDefaultsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, v)
+	})
+}
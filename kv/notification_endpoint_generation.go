@@ -0,0 +1,86 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/influxdata/influxdb"
+)
+
+// notificationEndpointGenerationBucket holds a counter per org, bumped on every change
+// to the notification endpoints of the org.
+var notificationEndpointGenerationBucket = []byte("notificationEndpointGenerationv1")
+
+var _ influxdb.NotificationEndpointGenerationService = (*Service)(nil)
+
+func (s *Service) initializeNotificationEndpointGenerations(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(notificationEndpointGenerationBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindNotificationEndpointGeneration returns the generation of the notification endpoints
+// of the org, zero until the first of them changes.
+func (s *Service) FindNotificationEndpointGeneration(ctx context.Context, orgID influxdb.ID) (uint64, error) {
+	var gen uint64
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		gen, err = s.findNotificationEndpointGeneration(tx, orgID)
+		return err
+	})
+	return gen, err
+}
+
+func (s *Service) findNotificationEndpointGeneration(tx Tx, orgID influxdb.ID) (uint64, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad organization id",
+			Err:  err,
+		}
+	}
+	b, err := tx.Bucket(notificationEndpointGenerationBucket)
+	if err != nil {
+		return 0, err
+	}
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != 8 {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "malformed notification endpoint generation (please report this error)",
+		}
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// bumpNotificationEndpointGeneration increments the generation of the notification
+// endpoints of the org.
+func (s *Service) bumpNotificationEndpointGeneration(tx Tx, orgID influxdb.ID) error {
+	if !orgID.Valid() {
+		return nil
+	}
+	gen, err := s.findNotificationEndpointGeneration(tx, orgID)
+	if err != nil {
+		return err
+	}
+	key, err := orgID.Encode()
+	if err != nil {
+		return err
+	}
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, gen+1)
+
+	b, err := tx.Bucket(notificationEndpointGenerationBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
@@ -0,0 +1,122 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/endpoint"
+)
+
+var notificationEndpointGroupBucket = []byte("notificationEndpointGroupv1")
+
+func (s *Service) initializeNotificationEndpointGroups(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(notificationEndpointGroupBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateNotificationEndpointGroup creates a new notification endpoint group and sets g.ID
+// with the new identifier.
+func (s *Service) CreateNotificationEndpointGroup(ctx context.Context, g *influxdb.NotificationEndpointGroup) error {
+	if err := g.Valid(); err != nil {
+		return err
+	}
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, g.OrgID); err != nil {
+			return err
+		}
+		g.ID = s.IDGenerator.ID()
+		return s.putNotificationEndpointGroup(ctx, tx, g)
+	})
+}
+
+func (s *Service) putNotificationEndpointGroup(ctx context.Context, tx Tx, g *influxdb.NotificationEndpointGroup) error {
+	key, err := g.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	v, err := json.Marshal(g)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(notificationEndpointGroupBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
+
+// FindNotificationEndpointGroupByID returns a single notification endpoint group by ID.
+func (s *Service) FindNotificationEndpointGroupByID(ctx context.Context, id influxdb.ID) (*influxdb.NotificationEndpointGroup, error) {
+	var g *influxdb.NotificationEndpointGroup
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		g, err = s.findNotificationEndpointGroupByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *Service) findNotificationEndpointGroupByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.NotificationEndpointGroup, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(notificationEndpointGroupBucket)
+	if err != nil {
+		return nil, err
+	}
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "notification endpoint group not found",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var g influxdb.NotificationEndpointGroup
+	if err := json.Unmarshal(v, &g); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &g, nil
+}
+
+// validNotificationEndpointGroup returns an error if the group of the notification
+// endpoint does not exist in the endpoint's org.
+func (s *Service) validNotificationEndpointGroup(ctx context.Context, tx Tx, edp influxdb.NotificationEndpoint) error {
+	groupID, ok := endpoint.GroupID(edp)
+	if !ok {
+		return nil
+	}
+	g, err := s.findNotificationEndpointGroupByID(ctx, tx, groupID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound || (err == nil && g.OrgID != edp.GetOrgID()) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("notification endpoint group %s does not exist in the organization", groupID),
+		}
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/endpoint"
+)
+
+// notificationEndpointHistoryBucket holds the prior configurations of notification
+// endpoints, keyed by the endpoint id and the big endian version, so the versions of
+// an endpoint are ordered.
+var notificationEndpointHistoryBucket = []byte("notificationEndpointHistoryv1")
+
+// MaxNotificationEndpointVersions is the number of prior configurations kept per
+// notification endpoint, the oldest are dropped first.
+const MaxNotificationEndpointVersions = 10
+
+var _ influxdb.NotificationEndpointHistoryService = (*Service)(nil)
+
+// notificationEndpointSnapshot is the stored form of a notification endpoint version.
+type notificationEndpointSnapshot struct {
+	ReplacedAt time.Time       `json:"replacedAt"`
+	Endpoint   json.RawMessage `json:"endpoint"`
+}
+
+func (s *Service) initializeNotificationEndpointHistory(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(notificationEndpointHistoryBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+func notificationEndpointHistoryPrefix(id influxdb.ID) ([]byte, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad notification endpoint id",
+			Err:  err,
+		}
+	}
+	return key, nil
+}
+
+func notificationEndpointHistoryKey(prefix []byte, version int) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], uint64(version))
+	return key
+}
+
+// FindNotificationEndpointHistory returns the kept prior configurations of the notification
+// endpoint, oldest first.
+func (s *Service) FindNotificationEndpointHistory(ctx context.Context, id influxdb.ID) ([]influxdb.NotificationEndpointVersion, error) {
+	var versions []influxdb.NotificationEndpointVersion
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findNotificationEndpointByID(ctx, tx, id); err != nil {
+			return err
+		}
+		var err error
+		versions, err = s.findNotificationEndpointHistory(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *Service) findNotificationEndpointHistory(tx Tx, id influxdb.ID) ([]influxdb.NotificationEndpointVersion, error) {
+	prefix, err := notificationEndpointHistoryPrefix(id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(notificationEndpointHistoryBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []influxdb.NotificationEndpointVersion{}
+	for k, v := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		version, err := decodeNotificationEndpointVersion(k[len(prefix):], v)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+func decodeNotificationEndpointVersion(k, v []byte) (influxdb.NotificationEndpointVersion, error) {
+	var snap notificationEndpointSnapshot
+	if err := json.Unmarshal(v, &snap); err != nil {
+		return influxdb.NotificationEndpointVersion{}, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "malformed notification endpoint version (please report this error)",
+			Err:  err,
+		}
+	}
+	edp, err := endpoint.UnmarshalJSON(snap.Endpoint)
+	if err != nil {
+		return influxdb.NotificationEndpointVersion{}, err
+	}
+	return influxdb.NotificationEndpointVersion{
+		Version:              int(binary.BigEndian.Uint64(k)),
+		ReplacedAt:           snap.ReplacedAt,
+		NotificationEndpoint: edp,
+	}, nil
+}
+
+// RestoreNotificationEndpointVersion updates the notification endpoint back to a prior
+// configuration. The configuration it replaces is kept as a new version, so a restore can
+// be undone like any other update. Secrets are restored as the key references they were,
+// holding the current secret values.
+func (s *Service) RestoreNotificationEndpointVersion(ctx context.Context, id influxdb.ID, version int, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	var edp influxdb.NotificationEndpoint
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		prefix, err := notificationEndpointHistoryPrefix(id)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(notificationEndpointHistoryBucket)
+		if err != nil {
+			return err
+		}
+		key := notificationEndpointHistoryKey(prefix, version)
+		v, err := b.Get(key)
+		if IsNotFound(err) {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  fmt.Sprintf("notification endpoint version %d not found", version),
+			}
+		}
+		if err != nil {
+			return err
+		}
+		prior, err := decodeNotificationEndpointVersion(key[len(prefix):], v)
+		if err != nil {
+			return err
+		}
+
+		edp, err = s.updateNotificationEndpoint(ctx, tx, id, prior.NotificationEndpoint, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edp, nil
+}
+
+// recordNotificationEndpointVersion keeps the configuration of the notification endpoint,
+// replaced at t, as its next version, and drops the versions beyond the kept ones.
+func (s *Service) recordNotificationEndpointVersion(tx Tx, edp influxdb.NotificationEndpoint, t time.Time) error {
+	prefix, err := notificationEndpointHistoryPrefix(edp.GetID())
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(notificationEndpointHistoryBucket)
+	if err != nil {
+		return err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		keys = append(keys, k)
+	}
+	next := 1
+	if len(keys) > 0 {
+		last := keys[len(keys)-1]
+		next = int(binary.BigEndian.Uint64(last[len(prefix):])) + 1
+	}
+
+	raw, err := json.Marshal(edp)
+	if err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	v, err := json.Marshal(notificationEndpointSnapshot{
+		ReplacedAt: t.UTC(),
+		Endpoint:   raw,
+	})
+	if err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	if err := b.Put(notificationEndpointHistoryKey(prefix, next), v); err != nil {
+		return err
+	}
+
+	for len(keys) >= MaxNotificationEndpointVersions {
+		if err := b.Delete(keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
+
+// deleteNotificationEndpointHistory deletes every kept version of the notification endpoint.
+func (s *Service) deleteNotificationEndpointHistory(tx Tx, id influxdb.ID) error {
+	prefix, err := notificationEndpointHistoryPrefix(id)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(notificationEndpointHistoryBucket)
+	if err != nil {
+		return err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// notificationEndpointNameIndex indexes notification endpoints by org and lowercased
+// name. Unlike the unique index of the endpoint store it ignores case, so it serves
+// case insensitive name lookups and name prefix searches without a full scan.
+var notificationEndpointNameIndex = []byte("notificationEndpointNameIndexv1")
+
+// initializeNotificationEndpointNameIndex creates the name index and indexes the
+// endpoints stored before it existed.
+func (s *Service) initializeNotificationEndpointNameIndex(ctx context.Context, tx Tx) error {
+	idx, err := tx.Bucket(notificationEndpointNameIndex)
+	if err != nil {
+		return err
+	}
+	cur, err := idx.Cursor()
+	if err != nil {
+		return err
+	}
+	if k, _ := cur.First(); k != nil {
+		return nil
+	}
+
+	return s.endpointStore.EntStore.Find(ctx, tx, FindOpts{
+		CaptureFn: func(_ []byte, v interface{}) error {
+			edp, ok := v.(influxdb.NotificationEndpoint)
+			if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+				return err
+			}
+			return s.putNotificationEndpointNameIndex(tx, edp)
+		},
+	})
+}
+
+// notificationEndpointNameIndexPrefix returns the index key prefix of the names of the
+// org starting with the name prefix.
+func notificationEndpointNameIndexPrefix(orgID influxdb.ID, namePrefix string) ([]byte, error) {
+	oID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad organization id",
+			Err:  err,
+		}
+	}
+	return append(oID, strings.ToLower(namePrefix)...), nil
+}
+
+// notificationEndpointNameIndexKey returns the index key of the endpoint: the org id,
+// the lowercased name and the endpoint id. The endpoint id is always the last
+// influxdb.IDLength bytes of the key.
+func notificationEndpointNameIndexKey(edp influxdb.NotificationEndpoint) ([]byte, error) {
+	prefix, err := notificationEndpointNameIndexPrefix(edp.GetOrgID(), edp.GetName())
+	if err != nil {
+		return nil, err
+	}
+	id, err := edp.GetID().Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad notification endpoint id",
+			Err:  err,
+		}
+	}
+	return append(prefix, id...), nil
+}
+
+func decodeNotificationEndpointNameIndexKey(k []byte) (influxdb.ID, error) {
+	var id influxdb.ID
+	if len(k) < 2*influxdb.IDLength {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "malformed notification endpoint name index key (please report this error)",
+		}
+	}
+	if err := id.Decode(k[len(k)-influxdb.IDLength:]); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bad notification endpoint id",
+			Err:  influxdb.ErrInvalidID,
+		}
+	}
+	return id, nil
+}
+
+func (s *Service) putNotificationEndpointNameIndex(tx Tx, edp influxdb.NotificationEndpoint) error {
+	key, err := notificationEndpointNameIndexKey(edp)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(notificationEndpointNameIndex)
+	if err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	if err := idx.Put(key, nil); err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	return nil
+}
+
+func (s *Service) removeNotificationEndpointNameIndex(tx Tx, edp influxdb.NotificationEndpoint) error {
+	key, err := notificationEndpointNameIndexKey(edp)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(notificationEndpointNameIndex)
+	if err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	if err := idx.Delete(key); err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	return nil
+}
+
+// reindexNotificationEndpointName moves the index entries of an updated endpoint from
+// its previous name to its current one. This covers the unique name index of the
+// endpoint store as well, which keeps the previous name otherwise.
+func (s *Service) reindexNotificationEndpointName(ctx context.Context, tx Tx, prevName string, edp influxdb.NotificationEndpoint) error {
+	if prevName != edp.GetName() {
+		prevEnt := Entity{UniqueKey: Encode(EncID(edp.GetOrgID()), EncString(prevName))}
+		if err := s.endpointStore.IndexStore.DeleteEnt(ctx, tx, prevEnt); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	}
+
+	prefix, err := notificationEndpointNameIndexPrefix(edp.GetOrgID(), prevName)
+	if err != nil {
+		return err
+	}
+	id, err := edp.GetID().Encode()
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(notificationEndpointNameIndex)
+	if err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+	if err := idx.Delete(append(prefix, id...)); err != nil {
+		return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+	}
+
+	return s.putNotificationEndpointNameIndex(tx, edp)
+}
+
+// findNotificationEndpointIDsByNamePrefix returns the ids of the endpoints of the org
+// whose lowercased name starts with the lowercased prefix, ordered by name.
+func (s *Service) findNotificationEndpointIDsByNamePrefix(ctx context.Context, tx Tx, orgID influxdb.ID, namePrefix string) ([]influxdb.ID, error) {
+	prefix, err := notificationEndpointNameIndexPrefix(orgID, namePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := tx.Bucket(notificationEndpointNameIndex)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := idx.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []influxdb.ID
+	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		id, err := decodeNotificationEndpointNameIndexKey(k)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// FindNotificationEndpointByName returns the notification endpoint of the org with the
+// name, ignoring case. When several names only differ in case the exact match wins.
+func (s *Service) FindNotificationEndpointByName(ctx context.Context, orgID influxdb.ID, name string) (influxdb.NotificationEndpoint, error) {
+	var found influxdb.NotificationEndpoint
+	err := s.kv.View(ctx, func(tx Tx) error {
+		ids, err := s.findNotificationEndpointIDsByNamePrefix(ctx, tx, orgID, name)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			edp, err := s.findNotificationEndpointByID(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(edp.GetName(), name) {
+				continue
+			}
+			if found == nil || edp.GetName() == name {
+				found = edp
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrNotificationEndpointNotFound
+	}
+	return found, nil
+}
+
+// FindNotificationEndpointsByNamePrefix returns the notification endpoints of the org
+// whose name starts with the prefix, ignoring case, ordered by name.
+func (s *Service) FindNotificationEndpointsByNamePrefix(ctx context.Context, orgID influxdb.ID, prefix string) ([]influxdb.NotificationEndpoint, error) {
+	edps := []influxdb.NotificationEndpoint{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		ids, err := s.findNotificationEndpointIDsByNamePrefix(ctx, tx, orgID, prefix)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			edp, err := s.findNotificationEndpointByID(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			edps = append(edps, edp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edps, nil
+}
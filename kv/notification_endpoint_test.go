@@ -2,11 +2,15 @@ package kv_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/endpoints"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification/endpoint"
 	influxdbtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -79,3 +83,721 @@ func initNotificationEndpointService(s kv.Store, f influxdbtesting.NotificationE
 		}
 	}
 }
+
+func TestNotificationEndpointService_NameIndex(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	newSlack := func(name string) influxdb.NotificationEndpoint {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   name,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x",
+		}
+		if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+			t.Fatalf("failed to create %q: %v", name, err)
+		}
+		return edp
+	}
+	searchNames := func(prefix string) []string {
+		t.Helper()
+		edps, err := svc.FindNotificationEndpointsByNamePrefix(ctx, org.ID, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, edp := range edps {
+			names = append(names, edp.GetName())
+		}
+		return names
+	}
+	assertNames := func(prefix string, want ...string) {
+		t.Helper()
+		got := searchNames(prefix)
+		if len(got) != len(want) {
+			t.Fatalf("prefix %q: expected %v, got %v", prefix, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("prefix %q: expected %v, got %v", prefix, want, got)
+			}
+		}
+	}
+
+	ops := newSlack("Slack Ops")
+	dev := newSlack("slack-dev")
+	newSlack("PagerDuty")
+
+	assertNames("slack", "Slack Ops", "slack-dev")
+	assertNames("SLACK O", "Slack Ops")
+	assertNames("", "PagerDuty", "Slack Ops", "slack-dev")
+
+	found, err := svc.FindNotificationEndpointByName(ctx, org.ID, "SLACK OPS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.GetID() != ops.GetID() {
+		t.Fatalf("expected endpoint %s, got %s", ops.GetID(), found.GetID())
+	}
+
+	t.Run("name filter", func(t *testing.T) {
+		findByName := func(name string) []influxdb.NotificationEndpoint {
+			t.Helper()
+			edps, n, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+				OrgID: &org.ID,
+				Name:  &name,
+				UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+					ResourceType: influxdb.NotificationEndpointResourceType,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(edps) {
+				t.Fatalf("expected a total of %d, got %d", len(edps), n)
+			}
+			return edps
+		}
+
+		if edps := findByName("Slack Ops"); len(edps) != 1 || edps[0].GetID() != ops.GetID() {
+			t.Fatalf("expected endpoint %s, got %v", ops.GetID(), edps)
+		}
+		// the filter is an exact match, unlike the index it is looked up in.
+		if edps := findByName("SLACK OPS"); len(edps) != 0 {
+			t.Fatalf("expected no endpoint, got %v", edps)
+		}
+		if edps := findByName("slack"); len(edps) != 0 {
+			t.Fatalf("expected no endpoint, got %v", edps)
+		}
+	})
+
+	t.Run("update moves the name", func(t *testing.T) {
+		ops.SetName("Ops Alerts")
+		if _, err := svc.UpdateNotificationEndpoint(ctx, ops.GetID(), ops, 1); err != nil {
+			t.Fatal(err)
+		}
+		assertNames("slack", "slack-dev")
+		assertNames("ops", "Ops Alerts")
+		if _, err := svc.FindNotificationEndpointByName(ctx, org.ID, "Slack Ops"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected the previous name not to be found, got %v", err)
+		}
+
+		// the previous name is free for reuse.
+		newSlack("Slack Ops")
+		assertNames("slack", "Slack Ops", "slack-dev")
+	})
+
+	t.Run("patch moves the name", func(t *testing.T) {
+		name := "Dev Alerts"
+		if _, err := svc.PatchNotificationEndpoint(ctx, dev.GetID(), influxdb.NotificationEndpointUpdate{Name: &name}); err != nil {
+			t.Fatal(err)
+		}
+		assertNames("slack-")
+		assertNames("dev", "Dev Alerts")
+		newSlack("slack-dev")
+		assertNames("slack-", "slack-dev")
+	})
+
+	t.Run("delete removes the name", func(t *testing.T) {
+		if _, _, err := svc.DeleteNotificationEndpoint(ctx, ops.GetID()); err != nil {
+			t.Fatal(err)
+		}
+		assertNames("ops")
+		if _, err := svc.FindNotificationEndpointByName(ctx, org.ID, "ops alerts"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected the deleted endpoint not to be found, got %v", err)
+		}
+	})
+}
+
+func TestNotificationEndpointService_CreateLimit(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	other := &influxdb.Organization{Name: "other"}
+	if err := svc.CreateOrganization(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	const max = 3
+	maxEndpoints := max
+	if err := svc.PutNotificationEndpointDefaults(ctx, &influxdb.NotificationEndpointDefaults{
+		OrgID:        org.ID,
+		MaxEndpoints: &maxEndpoints,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(orgID influxdb.ID, name string) (influxdb.NotificationEndpoint, error) {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &orgID,
+				Name:   name,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x",
+		}
+		return edp, svc.CreateNotificationEndpoint(ctx, edp, 1)
+	}
+
+	var first influxdb.NotificationEndpoint
+	for i := 0; i < max; i++ {
+		edp, err := create(org.ID, fmt.Sprintf("slack-%d", i))
+		if err != nil {
+			t.Fatalf("failed to create endpoint %d within the limit: %v", i, err)
+		}
+		if first == nil {
+			first = edp
+		}
+	}
+
+	if _, err := create(org.ID, "one too many"); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected the create beyond the limit to be forbidden, got %v", err)
+	}
+
+	// the limit is per org, orgs without defaults get the default limit.
+	if _, err := create(other.ID, "slack-0"); err != nil {
+		t.Fatalf("failed to create endpoint in another org: %v", err)
+	}
+
+	// deleting an endpoint frees its slot.
+	if _, _, err := svc.DeleteNotificationEndpoint(ctx, first.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := create(org.ID, "replacement"); err != nil {
+		t.Fatalf("failed to create endpoint after freeing a slot: %v", err)
+	}
+}
+
+func TestNotificationEndpointService_Group(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	other := &influxdb.Organization{Name: "other"}
+	if err := svc.CreateOrganization(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	dbGroup := &influxdb.NotificationEndpointGroup{OrgID: org.ID, Name: "database alerts"}
+	if err := svc.CreateNotificationEndpointGroup(ctx, dbGroup); err != nil {
+		t.Fatal(err)
+	}
+	otherGroup := &influxdb.NotificationEndpointGroup{OrgID: other.ID, Name: "database alerts"}
+	if err := svc.CreateNotificationEndpointGroup(ctx, otherGroup); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(name string, groupID *influxdb.ID) error {
+		return svc.CreateNotificationEndpoint(ctx, &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:   &org.ID,
+				Name:    name,
+				Status:  influxdb.Active,
+				GroupID: groupID,
+			},
+			URL: "https://hooks.slack.com/services/x",
+		}, 1)
+	}
+
+	if err := create("db-pager", &dbGroup.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("db-slack", &dbGroup.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("ungrouped", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("filter by group", func(t *testing.T) {
+		edps, n, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			GroupID: &dbGroup.ID,
+			UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Fatalf("expected 2 endpoints in the group, got %d", n)
+		}
+		for _, edp := range edps {
+			if groupID, ok := endpoint.GroupID(edp); !ok || groupID != dbGroup.ID {
+				t.Errorf("expected endpoint %q to belong to group %s", edp.GetName(), dbGroup.ID)
+			}
+		}
+	})
+
+	t.Run("unknown group is rejected", func(t *testing.T) {
+		unknown := influxdb.ID(0xdead)
+		if err := create("unknown-group", &unknown); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid error, got %v", err)
+		}
+	})
+
+	t.Run("group of another org is rejected", func(t *testing.T) {
+		if err := create("other-group", &otherGroup.ID); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid error, got %v", err)
+		}
+	})
+}
+
+func TestNotificationEndpointService_NotificationBudget(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:                   &org.ID,
+			Name:                    "slack",
+			Status:                  influxdb.Active,
+			MaxNotificationsPerHour: 2,
+		},
+		URL: "https://hooks.slack.com/services/x",
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := svc.FindNotificationEndpointByID(ctx, edp.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if max := endpoint.MaxNotificationsPerHour(found); max != 2 {
+		t.Fatalf("expected a budget of 2 notifications per hour, got %d", max)
+	}
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, sentAt := range []time.Time{now.Add(-2 * time.Hour), now.Add(-30 * time.Minute), now.Add(-time.Minute)} {
+		if err := svc.RecordNotificationSent(ctx, edp.GetID(), sentAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	budget, err := svc.FindNotificationEndpointBudget(ctx, edp.GetID(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget.Max != 2 || budget.Sent != 2 {
+		t.Fatalf("expected 2 of 2 notifications sent, got %+v", budget)
+	}
+	if !budget.Exceeded() {
+		t.Fatal("expected the budget to be exceeded")
+	}
+
+	// sends older than an hour stop counting against the budget.
+	budget, err = svc.FindNotificationEndpointBudget(ctx, edp.GetID(), now.Add(45*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget.Sent != 1 || budget.Exceeded() {
+		t.Fatalf("expected 1 of 2 notifications sent, got %+v", budget)
+	}
+
+	if err := svc.RecordNotificationSent(ctx, influxdb.ID(0xdead), now); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected recording a send to an unknown endpoint to fail with not found, got %v", err)
+	}
+}
+
+func TestNotificationEndpointService_SecretRotation(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(name string, policy *endpoint.RotationPolicy) (influxdb.NotificationEndpoint, error) {
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:          &org.ID,
+				Name:           name,
+				Status:         influxdb.Active,
+				SecretRotation: policy,
+			},
+			URL: "https://hooks.slack.com/services/x",
+		}
+		return edp, svc.CreateNotificationEndpoint(ctx, edp, 1)
+	}
+
+	daily, err := create("daily", &endpoint.RotationPolicy{Interval: influxdb.Duration{Duration: 24 * time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := create("never", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := create("too often", &endpoint.RotationPolicy{Interval: influxdb.Duration{Duration: time.Minute}}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected a rotation interval under an hour to be invalid, got %v", err)
+	}
+
+	found, err := svc.FindNotificationEndpointByID(ctx, daily.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, ok := endpoint.SecretRotation(found)
+	if !ok {
+		t.Fatal("expected the endpoint to have a secret rotation policy")
+	}
+	if want := now.Add(24 * time.Hour); policy.NextRotationAt == nil || !policy.NextRotationAt.Equal(want) {
+		t.Fatalf("expected the next rotation at %s, got %v", want, policy.NextRotationAt)
+	}
+
+	due, err := svc.FindNotificationEndpointsDueForSecretRotation(ctx, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no endpoints due for rotation, got %d", len(due))
+	}
+	due, err = svc.FindNotificationEndpointsDueForSecretRotation(ctx, now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].GetID() != daily.GetID() {
+		t.Fatalf("expected the daily endpoint to be due for rotation, got %v", due)
+	}
+}
+
+func TestNotificationEndpointService_Generation(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	other := &influxdb.Organization{Name: "other"}
+	if err := svc.CreateOrganization(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	generation := func(orgID influxdb.ID) uint64 {
+		t.Helper()
+		gen, err := svc.FindNotificationEndpointGeneration(ctx, orgID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gen
+	}
+
+	if gen := generation(org.ID); gen != 0 {
+		t.Fatalf("expected generation 0 before any change, got %d", gen)
+	}
+
+	edp := &endpoint.Slack{
+		Base: endpoint.Base{
+			OrgID:  &org.ID,
+			Name:   "slack",
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x",
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+	afterCreate := generation(org.ID)
+	if afterCreate == 0 {
+		t.Fatal("expected the generation to change after a create")
+	}
+
+	name := "renamed"
+	if _, err := svc.PatchNotificationEndpoint(ctx, edp.GetID(), influxdb.NotificationEndpointUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	afterPatch := generation(org.ID)
+	if afterPatch == afterCreate {
+		t.Fatal("expected the generation to change after a patch")
+	}
+
+	if _, _, err := svc.DeleteNotificationEndpoint(ctx, edp.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if generation(org.ID) == afterPatch {
+		t.Fatal("expected the generation to change after a delete")
+	}
+
+	if gen := generation(other.ID); gen != 0 {
+		t.Fatalf("expected the generation of another org to be untouched, got %d", gen)
+	}
+}
+
+func TestNotificationEndpointService_History(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	newSlack := func(url string) *endpoint.Slack {
+		return &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   "slack",
+				Status: influxdb.Active,
+			},
+			URL: url,
+		}
+	}
+	update := func(id influxdb.ID, url string) {
+		t.Helper()
+		edp := newSlack(url)
+		edp.ID = &id
+		if _, err := svc.UpdateNotificationEndpoint(ctx, id, edp, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := func(id influxdb.ID) []influxdb.NotificationEndpointVersion {
+		t.Helper()
+		versions, err := svc.FindNotificationEndpointHistory(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return versions
+	}
+
+	edp := newSlack("https://hooks.slack.com/services/v1")
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+	if versions := history(edp.GetID()); len(versions) != 0 {
+		t.Fatalf("expected no history before any update, got %d versions", len(versions))
+	}
+
+	update(edp.GetID(), "https://hooks.slack.com/services/v2")
+	update(edp.GetID(), "https://hooks.slack.com/services/v3")
+
+	versions := history(edp.GetID())
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	for i, want := range []string{"https://hooks.slack.com/services/v1", "https://hooks.slack.com/services/v2"} {
+		if versions[i].Version != i+1 {
+			t.Errorf("expected version %d, got %d", i+1, versions[i].Version)
+		}
+		if got := versions[i].NotificationEndpoint.(*endpoint.Slack).URL; got != want {
+			t.Errorf("expected version %d to have url %q, got %q", i+1, want, got)
+		}
+	}
+
+	restored, err := svc.RestoreNotificationEndpointVersion(ctx, edp.GetID(), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.(*endpoint.Slack).URL; got != "https://hooks.slack.com/services/v1" {
+		t.Fatalf("expected the restored url of version 1, got %q", got)
+	}
+	got, err := svc.FindNotificationEndpointByID(ctx, edp.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url := got.(*endpoint.Slack).URL; url != "https://hooks.slack.com/services/v1" {
+		t.Fatalf("expected the stored url of version 1, got %q", url)
+	}
+	versions = history(edp.GetID())
+	if len(versions) != 3 || versions[2].NotificationEndpoint.(*endpoint.Slack).URL != "https://hooks.slack.com/services/v3" {
+		t.Fatal("expected the restore to keep the replaced configuration as version 3")
+	}
+
+	if _, err := svc.RestoreNotificationEndpointVersion(ctx, edp.GetID(), 42, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected restoring an unknown version to be not found, got %v", err)
+	}
+
+	for i := 0; i < kv.MaxNotificationEndpointVersions; i++ {
+		update(edp.GetID(), fmt.Sprintf("https://hooks.slack.com/services/bulk-%d", i))
+	}
+	versions = history(edp.GetID())
+	if len(versions) != kv.MaxNotificationEndpointVersions {
+		t.Fatalf("expected %d kept versions, got %d", kv.MaxNotificationEndpointVersions, len(versions))
+	}
+	if versions[0].Version != 4 {
+		t.Fatalf("expected the oldest versions to be dropped, oldest kept is %d", versions[0].Version)
+	}
+
+	if _, _, err := svc.DeleteNotificationEndpoint(ctx, edp.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindNotificationEndpointHistory(ctx, edp.GetID()); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected the history of a deleted endpoint to be not found, got %v", err)
+	}
+}
+
+func TestNotificationEndpointService_FindOrder(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org1 := &influxdb.Organization{Name: "org1"}
+	org2 := &influxdb.Organization{Name: "org2"}
+	for _, o := range []*influxdb.Organization{org1, org2} {
+		if err := svc.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// IDs are handed out in decreasing order, so the store's key order is the
+	// reverse of the creation order.
+	next := influxdb.ID(1 << 40)
+	svc.IDGenerator = mock.IDGenerator{IDFn: func() influxdb.ID {
+		next--
+		return next
+	}}
+	t0 := time.Date(2006, time.July, 13, 4, 19, 10, 0, time.UTC)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: t0}
+
+	create := func(org *influxdb.Organization, name string) {
+		t.Helper()
+		edp := &endpoint.Slack{
+			Base: endpoint.Base{
+				OrgID:  &org.ID,
+				Name:   name,
+				Status: influxdb.Active,
+			},
+			URL: "https://hooks.slack.com/services/x",
+		}
+		if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+			t.Fatalf("failed to create %q: %v", name, err)
+		}
+	}
+	create(org1, "a")
+	create(org1, "b")
+	create(org1, "c")
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: t0.Add(time.Minute)}
+	create(org1, "d")
+	create(org2, "a")
+
+	find := func(opts influxdb.FindOptions) []string {
+		t.Helper()
+		edps, _, err := svc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, edp := range edps {
+			org := "org1"
+			if edp.GetOrgID() == org2.ID {
+				org = "org2"
+			}
+			got = append(got, org+"/"+edp.GetName())
+		}
+		return got
+	}
+	assertOrder := func(opts influxdb.FindOptions, want ...string) {
+		t.Helper()
+		// the order must not depend on the run.
+		for i := 0; i < 3; i++ {
+			if got := find(opts); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("%+v: expected %v, got %v", opts, want, got)
+			}
+		}
+	}
+
+	t.Run("default order breaks name ties by creation time", func(t *testing.T) {
+		assertOrder(influxdb.FindOptions{}, "org1/a", "org2/a", "org1/b", "org1/c", "org1/d")
+	})
+
+	t.Run("creation time ties are broken by ID", func(t *testing.T) {
+		assertOrder(influxdb.FindOptions{SortBy: "createdAt"}, "org1/c", "org1/b", "org1/a", "org2/a", "org1/d")
+		assertOrder(influxdb.FindOptions{SortBy: "createdAt", Descending: true}, "org1/d", "org2/a", "org1/a", "org1/b", "org1/c")
+	})
+
+	t.Run("pages do not overlap", func(t *testing.T) {
+		var paged []string
+		for offset := 0; offset < 5; offset += 2 {
+			paged = append(paged, find(influxdb.FindOptions{SortBy: "createdAt", Offset: offset, Limit: 2})...)
+		}
+		want := find(influxdb.FindOptions{SortBy: "createdAt"})
+		if fmt.Sprint(paged) != fmt.Sprint(want) {
+			t.Fatalf("expected pages to add up to %v, got %v", want, paged)
+		}
+	})
+}
@@ -166,6 +166,30 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeNotificationEndpointNameIndex(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeNotificationEndpointGroups(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeNotificationEndpointDefaults(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeNotificationEndpointSends(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeNotificationEndpointGenerations(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeNotificationEndpointHistory(ctx, tx); err != nil {
+			return err
+		}
+
 		return s.initializeUsers(ctx, tx)
 	})
 }
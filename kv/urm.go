@@ -311,6 +311,76 @@ func (s *Service) DeleteUserResourceMapping(ctx context.Context, resourceID infl
 	})
 }
 
+// TransferResourceOwnership replaces the owner mapping of fromUserID on the resource with
+// an owner mapping for toUserID. Any mapping toUserID already had on the resource is replaced.
+func (s *Service) TransferResourceOwnership(ctx context.Context, resourceType influxdb.ResourceType, resourceID, fromUserID, toUserID influxdb.ID) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.transferResourceOwnership(ctx, tx, resourceType, resourceID, fromUserID, toUserID)
+	})
+}
+
+func (s *Service) transferResourceOwnership(ctx context.Context, tx Tx, resourceType influxdb.ResourceType, resourceID, fromUserID, toUserID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	from := influxdb.UserResourceMappingFilter{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		UserID:       fromUserID,
+		UserType:     influxdb.Owner,
+	}
+	m, err := s.findUserResourceMapping(ctx, tx, from)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("user %s does not own resource %s", fromUserID, resourceID),
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.findUserByID(ctx, tx, toUserID); err != nil {
+		return err
+	}
+
+	if err := s.deleteUserResourceMapping(ctx, tx, from); err != nil {
+		return err
+	}
+	if m.ResourceType == influxdb.OrgsResourceType {
+		if err := s.deleteOrgDependentMappings(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+
+	// the new owner may already be a member of the resource.
+	to := influxdb.UserResourceMappingFilter{
+		ResourceID: resourceID,
+		UserID:     toUserID,
+	}
+	existing, err := s.findUserResourceMapping(ctx, tx, to)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if existing != nil {
+		if err := s.deleteUserResourceMapping(ctx, tx, to); err != nil {
+			return err
+		}
+		if existing.ResourceType == influxdb.OrgsResourceType {
+			if err := s.deleteOrgDependentMappings(ctx, tx, existing); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.createUserResourceMapping(ctx, tx, &influxdb.UserResourceMapping{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		UserID:       toUserID,
+		UserType:     influxdb.Owner,
+	})
+}
+
 func (s *Service) deleteUserResourceMapping(ctx context.Context, tx Tx, filter influxdb.UserResourceMappingFilter) error {
 	// TODO(goller): do we really need to find here? Seems like a Get is
 	// good enough.
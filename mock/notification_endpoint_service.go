@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/influxdata/influxdb"
 )
@@ -24,6 +25,9 @@ type NotificationEndpointService struct {
 	PatchNotificationEndpointCalls    SafeCount
 	DeleteNotificationEndpointF       func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error)
 	DeleteNotificationEndpointCalls   SafeCount
+
+	RecordValidationF     func(ctx context.Context, id influxdb.ID, t time.Time, ok bool) (influxdb.NotificationEndpoint, error)
+	RecordValidationCalls SafeCount
 }
 
 func NewNotificationEndpointService() *NotificationEndpointService {
@@ -48,6 +52,9 @@ func NewNotificationEndpointService() *NotificationEndpointService {
 		DeleteNotificationEndpointF: func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
 			return nil, 0, nil
 		},
+		RecordValidationF: func(ctx context.Context, id influxdb.ID, t time.Time, ok bool) (influxdb.NotificationEndpoint, error) {
+			return nil, nil
+		},
 	}
 }
 
@@ -89,3 +96,9 @@ func (s *NotificationEndpointService) DeleteNotificationEndpoint(ctx context.Con
 	defer s.DeleteNotificationEndpointCalls.IncrFn()()
 	return s.DeleteNotificationEndpointF(ctx, id)
 }
+
+// RecordValidation records the outcome of a connectivity check of a notification endpoint.
+func (s *NotificationEndpointService) RecordValidation(ctx context.Context, id influxdb.ID, t time.Time, ok bool) (influxdb.NotificationEndpoint, error) {
+	defer s.RecordValidationCalls.IncrFn()()
+	return s.RecordValidationF(ctx, id, t, ok)
+}
@@ -0,0 +1,109 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/influxdata/influxdb"
+)
+
+// IPLookup resolves a host to its IP addresses, e.g. net.DefaultResolver.LookupIPAddr.
+type IPLookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+// privateNetworks are the ranges, beyond loopback and link-local, that are not
+// reachable from the internet.
+var privateNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// IsPrivateAddress reports whether the ip is a loopback, link-local, private or
+// unspecified address, e.g. the 169.254.169.254 cloud metadata address.
+func IsPrivateAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPublicAddresses returns an error if a url the notification endpoint sends to
+// resolves to a private address, so multi-tenant hosts can keep endpoints from
+// reaching internal services. The check happens when the endpoint is configured, a
+// host resolving to another address later is not caught.
+func CheckPublicAddresses(ctx context.Context, edp influxdb.NotificationEndpoint, lookup IPLookup) error {
+	for _, rawURL := range configuredURLs(edp) {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Hostname() == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("notification endpoint url %q must be an absolute url", rawURL),
+			}
+		}
+
+		host := u.Hostname()
+		var ips []net.IP
+		if ip := net.ParseIP(host); ip != nil {
+			ips = []net.IP{ip}
+		} else {
+			addrs, err := lookup(ctx, host)
+			if err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("unable to resolve the host of notification endpoint url %q", rawURL),
+					Err:  err,
+				}
+			}
+			for _, addr := range addrs {
+				ips = append(ips, addr.IP)
+			}
+		}
+
+		for _, ip := range ips {
+			if IsPrivateAddress(ip) {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("notification endpoint url %q resolves to the private address %s", rawURL, ip),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// configuredURLs returns the urls the notification endpoint sends to that are set by
+// its owner. The PagerDuty events url is fixed, so it is left out.
+func configuredURLs(edp influxdb.NotificationEndpoint) []string {
+	switch e := edp.(type) {
+	case *HTTP:
+		return append([]string{e.URL}, e.FailoverURLs...)
+	case *Slack:
+		return []string{e.URL}
+	case *Discord:
+		return []string{e.URL}
+	}
+	return nil
+}
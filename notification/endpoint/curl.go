@@ -0,0 +1,230 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// curlNoArgOptions are the curl options that change nothing about the request an
+// endpoint sends, they are accepted and ignored.
+var curlNoArgOptions = map[string]bool{
+	"-s": true, "--silent": true,
+	"-S": true, "--show-error": true,
+	"-v": true, "--verbose": true,
+	"-i": true, "--include": true,
+	"-L": true, "--location": true,
+	"-f": true, "--fail": true,
+	"--compressed": true,
+}
+
+// curlArgOptions maps the curl options that take an argument to their long form.
+var curlArgOptions = map[string]string{
+	"-X": "--request", "--request": "--request",
+	"-H": "--header", "--header": "--header",
+	"-u": "--user", "--user": "--user",
+	"-d": "--data", "--data": "--data", "--data-raw": "--data", "--data-binary": "--data",
+	"--url": "--url",
+}
+
+// ParseCurl turns a curl command, e.g. one tested in a shell, into an http endpoint. The
+// url, method, headers and body are kept, the body as the content template. Basic auth
+// and bearer tokens become the endpoint's secrets. The base of the endpoint is left for
+// the caller to fill in.
+func ParseCurl(cmd string) (*HTTP, error) {
+	args, err := splitCurlCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, curlError("command must start with curl")
+	}
+
+	edp := &HTTP{AuthMethod: "none"}
+	var data *string
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if curlNoArgOptions[arg] {
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			if edp.URL != "" {
+				return nil, curlError("only one url is supported")
+			}
+			edp.URL = arg
+			continue
+		}
+
+		opt, val, ok := splitCurlOption(arg)
+		if !ok {
+			return nil, curlError(fmt.Sprintf("unsupported option %q", arg))
+		}
+		if val == nil {
+			if i+1 >= len(args) {
+				return nil, curlError(fmt.Sprintf("option %q is missing its argument", arg))
+			}
+			i++
+			val = &args[i]
+		}
+
+		switch opt {
+		case "--request":
+			edp.Method = *val
+		case "--header":
+			if err := edp.setCurlHeader(*val); err != nil {
+				return nil, err
+			}
+		case "--user":
+			if edp.AuthMethod == "bearer" {
+				return nil, curlError("basic auth can't be combined with a bearer token")
+			}
+			user, pass := splitCurlCredentials(*val)
+			edp.AuthMethod = "basic"
+			edp.Username = influxdb.SecretField{Value: &user}
+			edp.Password = influxdb.SecretField{Value: &pass}
+		case "--data":
+			data = val
+		case "--url":
+			if edp.URL != "" {
+				return nil, curlError("only one url is supported")
+			}
+			edp.URL = *val
+		}
+	}
+
+	if edp.URL == "" {
+		return nil, curlError("url must be provided")
+	}
+	if u, err := url.Parse(edp.URL); err != nil || u.Host == "" {
+		return nil, curlError(fmt.Sprintf("url %q must be an absolute url", edp.URL))
+	}
+	if data != nil {
+		edp.ContentTemplate = *data
+	}
+	// like curl, a body turns the default GET into a POST.
+	if edp.Method == "" {
+		edp.Method = http.MethodGet
+		if data != nil {
+			edp.Method = http.MethodPost
+		}
+	}
+	return edp, nil
+}
+
+// setCurlHeader adds a header of the curl command. A bearer authorization header
+// becomes the endpoint's token.
+func (s *HTTP) setCurlHeader(header string) error {
+	parts := strings.SplitN(header, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return curlError(fmt.Sprintf("header %q must be formatted as name: value", header))
+	}
+	name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	if strings.EqualFold(name, "Authorization") && len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+		if s.AuthMethod == "basic" {
+			return curlError("basic auth can't be combined with a bearer token")
+		}
+		token := strings.TrimSpace(value[7:])
+		s.AuthMethod = "bearer"
+		s.Token = influxdb.SecretField{Value: &token}
+		return nil
+	}
+
+	if s.Headers == nil {
+		s.Headers = make(map[string]string)
+	}
+	s.Headers[name] = value
+	return nil
+}
+
+// splitCurlOption returns the long form of an option taking an argument, along with the
+// argument when it is attached to the option, e.g. -XPOST or --request=POST.
+func splitCurlOption(arg string) (string, *string, bool) {
+	if opt, ok := curlArgOptions[arg]; ok {
+		return opt, nil, true
+	}
+	if strings.HasPrefix(arg, "--") {
+		if i := strings.Index(arg, "="); i > 0 {
+			if opt, ok := curlArgOptions[arg[:i]]; ok {
+				val := arg[i+1:]
+				return opt, &val, true
+			}
+		}
+		return "", nil, false
+	}
+	if len(arg) > 2 {
+		if opt, ok := curlArgOptions[arg[:2]]; ok {
+			val := arg[2:]
+			return opt, &val, true
+		}
+	}
+	return "", nil, false
+}
+
+func splitCurlCredentials(creds string) (string, string) {
+	parts := strings.SplitN(creds, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// splitCurlCommand splits a shell command into its arguments, honoring single and double
+// quotes, backslash escapes and line continuations.
+func splitCurlCommand(cmd string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			escaped = false
+			// a backslash before a newline continues the command on the next line.
+			if r != '\n' {
+				cur.WriteRune(r)
+				inArg = true
+			}
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, curlError("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+func curlError(msg string) error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "invalid curl command: " + msg,
+	}
+}
@@ -0,0 +1,45 @@
+package endpoint
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// providerHosts maps the hosts of known webhook receivers to the endpoint type
+// that delivers to them. Teams accepts plain JSON posts, so it is served by the
+// HTTP endpoint.
+var providerHosts = []struct {
+	host string
+	typ  string
+}{
+	{host: "hooks.slack.com", typ: SlackType},
+	{host: "slack.com", typ: SlackType},
+	{host: "events.pagerduty.com", typ: PagerDutyType},
+	{host: "discord.com", typ: DiscordType},
+	{host: "discordapp.com", typ: DiscordType},
+	{host: "webhook.office.com", typ: HTTPType},
+	{host: "outlook.office.com", typ: HTTPType},
+}
+
+// Detect guesses the endpoint type of a webhook receiver URL from its host. The
+// bool is false when the host is not a known provider, in which case the generic
+// HTTP type is returned.
+func Detect(rawURL string) (TypeInfo, bool, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return TypeInfo{}, false, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url must be an absolute url",
+		}
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, p := range providerHosts {
+		if host == p.host || strings.HasSuffix(host, "."+p.host) {
+			return typeInfo(p.typ), true, nil
+		}
+	}
+	return typeInfo(HTTPType), false, nil
+}
@@ -0,0 +1,104 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.NotificationEndpoint = &Discord{}
+
+// maxDiscordUsernameLength is the longest username discord accepts for a webhook message.
+const maxDiscordUsernameLength = 80
+
+// Discord is the notification endpoint config of discord.
+type Discord struct {
+	Base
+	// URL is the discord webhook URL, the webhook token is part of it.
+	// example: https://discord.com/api/webhooks/123/abc
+	URL string `json:"url"`
+	// Username overrides the default username of the webhook.
+	Username string `json:"username,omitempty"`
+	// AvatarURL overrides the default avatar of the webhook.
+	AvatarURL string `json:"avatarURL,omitempty"`
+}
+
+// BackfillSecretKeys is a no-op, discord webhooks have no secret fields.
+func (s *Discord) BackfillSecretKeys() {}
+
+// Normalize cleans up the user provided configuration.
+func (s *Discord) Normalize() {
+	s.Base.normalize()
+	s.URL = normalizeURL(s.URL)
+	s.Username = strings.TrimSpace(s.Username)
+	s.AvatarURL = strings.TrimSpace(s.AvatarURL)
+}
+
+// SecretFields return available secret fields.
+func (s Discord) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Discord) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.URL == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "discord endpoint URL must be provided",
+		}
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("discord endpoint URL is invalid: %s", err.Error()),
+		}
+	}
+	if !strings.EqualFold(u.Scheme, "https") || u.Host == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "discord endpoint URL must be an absolute https url",
+		}
+	}
+	if utf8.RuneCountInString(s.Username) > maxDiscordUsernameLength {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("discord endpoint username must not be longer than %d characters", maxDiscordUsernameLength),
+		}
+	}
+	if s.AvatarURL != "" {
+		if u, err := url.Parse(s.AvatarURL); err != nil || u.Host == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "discord endpoint avatar URL must be an absolute url",
+			}
+		}
+	}
+	return nil
+}
+
+type discordAlias Discord
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Discord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			discordAlias
+			Type string `json:"type"`
+		}{
+			discordAlias: discordAlias(s),
+			Type:         s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Discord) Type() string {
+	return DiscordType
+}
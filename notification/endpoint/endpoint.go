@@ -1,10 +1,16 @@
 package endpoint
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
 )
 
 // types of endpoints.
@@ -12,12 +18,14 @@ const (
 	SlackType     = "slack"
 	PagerDutyType = "pagerduty"
 	HTTPType      = "http"
+	DiscordType   = "discord"
 )
 
 var typeToEndpoint = map[string](func() influxdb.NotificationEndpoint){
 	SlackType:     func() influxdb.NotificationEndpoint { return &Slack{} },
 	PagerDutyType: func() influxdb.NotificationEndpoint { return &PagerDuty{} },
 	HTTPType:      func() influxdb.NotificationEndpoint { return &HTTP{} },
+	DiscordType:   func() influxdb.NotificationEndpoint { return &Discord{} },
 }
 
 // UnmarshalJSON will convert the bytes to notification endpoint.
@@ -39,13 +47,534 @@ func UnmarshalJSON(b []byte) (influxdb.NotificationEndpoint, error) {
 	converted := convertedFunc()
 	if err := json.Unmarshal(b, converted); err != nil {
 		return nil, &influxdb.Error{
-			Code: influxdb.EInternal,
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s notification endpoint", raw.Type),
 			Err:  err,
 		}
 	}
+	if IsNil(converted) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s notification endpoint", raw.Type),
+		}
+	}
 	return converted, nil
 }
 
+// UnmarshalJSONStrict converts the bytes to a notification endpoint like UnmarshalJSON, but
+// rejects fields the endpoint doesn't have, and its errors name the field, and the offset
+// in the body when json reports one, at fault. Fields of the body that are not part of the
+// endpoint, like the labels of a create request, are allowed when listed in extraFields.
+func UnmarshalJSONStrict(b []byte, extraFields ...string) (influxdb.NotificationEndpoint, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, strictError("", err)
+	}
+	var typ string
+	if raw, ok := fields["type"]; ok {
+		if err := json.Unmarshal(raw, &typ); err != nil {
+			return nil, strictError("", err)
+		}
+	}
+	convertedFunc, ok := typeToEndpoint[typ]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid notification endpoint type %q", typ),
+		}
+	}
+
+	converted := convertedFunc()
+	if err := json.Unmarshal(b, converted); err != nil {
+		return nil, strictError(typ, err)
+	}
+
+	// json reports the first unknown field it meets without an offset, so every
+	// field is checked on its own, in order of name, to find them all the same way.
+	known := map[string]bool{"type": true}
+	for _, f := range extraFields {
+		known[f] = true
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, err := json.Marshal(map[string]json.RawMessage{name: fields[name]})
+		if err != nil {
+			return nil, strictError(typ, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(field))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(convertedFunc()); err != nil {
+			return nil, strictError(typ, err)
+		}
+	}
+	return converted, nil
+}
+
+// strictError describes a json decoding error by the field and offset at fault.
+func strictError(typ string, err error) error {
+	msg := "invalid notification endpoint"
+	if typ != "" {
+		msg = fmt.Sprintf("invalid %s notification endpoint", typ)
+	}
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		msg = fmt.Sprintf("%s: syntax error at offset %d: %v", msg, e.Offset, e)
+	case *json.UnmarshalTypeError:
+		msg = fmt.Sprintf("%s: field %q at offset %d must be %s, got %s", msg, e.Field, e.Offset, e.Type, e.Value)
+	default:
+		msg = fmt.Sprintf("%s: %v", msg, strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  msg,
+	}
+}
+
+// IsNil reports whether the endpoint is nil, including a nil pointer to one of
+// the concrete endpoint types held by a non nil interface. Calling Base on such
+// an endpoint panics.
+func IsNil(edp influxdb.NotificationEndpoint) bool {
+	switch e := edp.(type) {
+	case nil:
+		return true
+	case *Slack:
+		return e == nil
+	case *PagerDuty:
+		return e == nil
+	case *HTTP:
+		return e == nil
+	case *Discord:
+		return e == nil
+	}
+	return false
+}
+
+// Validate decodes the json body of a notification endpoint and checks that it
+// is valid, without touching any storage. A body without an ID, such as a create
+// request, is validated as if it was already assigned one, and secret values
+// are validated as if their keys were already backfilled.
+// The returned error is always an *influxdb.Error with the EInvalid code.
+func Validate(b []byte) error {
+	edp, err := UnmarshalJSON(b)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "endpoint/Validate",
+			Err:  err,
+		}
+	}
+	if !edp.GetID().Valid() {
+		edp.SetID(influxdb.ID(1))
+	}
+	edp.BackfillSecretKeys()
+	if err := edp.Valid(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "endpoint/Validate",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+// secretPlaceholderer is implemented by endpoints that can swap their secret
+// references for empty placeholder values.
+type secretPlaceholderer interface {
+	placeholderSecrets()
+}
+
+// namedSecretFielder is implemented by endpoints that can list every secret field,
+// set or not, by its json name.
+type namedSecretFielder interface {
+	namedSecretFields() map[string]influxdb.SecretField
+}
+
+// SetSecretFields returns the secret fields of the notification endpoint that carry a
+// value or a key, in the order of their json names. Unlike SecretFields, the values of
+// a new endpoint are included before BackfillSecretKeys assigns them keys.
+func SetSecretFields(edp influxdb.NotificationEndpoint) []influxdb.SecretField {
+	fielder, ok := edp.(namedSecretFielder)
+	if !ok {
+		return edp.SecretFields()
+	}
+	fields := fielder.namedSecretFields()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var flds []influxdb.SecretField
+	for _, name := range names {
+		if fld := fields[name]; fld.Value != nil || fld.Key != "" {
+			flds = append(flds, fld)
+		}
+	}
+	return flds
+}
+
+// baser is implemented by every endpoint through its embedded Base.
+type baser interface {
+	base() *Base
+}
+
+func (b *Base) base() *Base {
+	return b
+}
+
+// warner is implemented by endpoints that can report risky, but valid, configuration.
+type warner interface {
+	Warnings() []string
+}
+
+// Warnings returns the soft validation warnings of the notification endpoint.
+// Unlike Valid, warnings never block an endpoint from being stored.
+func Warnings(edp influxdb.NotificationEndpoint) []string {
+	if w, ok := edp.(warner); ok {
+		return w.Warnings()
+	}
+	return nil
+}
+
+// insecureURLWarning returns a warning if the url sends notifications unencrypted.
+func insecureURLWarning(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(u.Scheme, "http") {
+		return ""
+	}
+	return "insecure transport: url uses http, notifications are sent unencrypted"
+}
+
+// TelemetryEnabled reports whether the notification endpoint may be included in
+// aggregate usage reporting. Anything gathering usage about endpoints must check it.
+func TelemetryEnabled(edp influxdb.NotificationEndpoint) bool {
+	if b, ok := edp.(baser); ok {
+		return !b.base().NoTelemetry
+	}
+	return true
+}
+
+// FallbackEndpointID returns the ID of the notification endpoint's fallback, if it has one.
+func FallbackEndpointID(edp influxdb.NotificationEndpoint) (influxdb.ID, bool) {
+	if b, ok := edp.(baser); ok && b.base().FallbackEndpointID != nil {
+		return *b.base().FallbackEndpointID, true
+	}
+	return 0, false
+}
+
+// LastValidatedAt returns when a connectivity check of the notification endpoint last
+// succeeded, nil if none has.
+func LastValidatedAt(edp influxdb.NotificationEndpoint) *time.Time {
+	if b, ok := edp.(baser); ok {
+		return b.base().LastValidatedAt
+	}
+	return nil
+}
+
+// SetLastValidatedAt sets when a connectivity check of the notification endpoint last
+// succeeded.
+func SetLastValidatedAt(edp influxdb.NotificationEndpoint, t *time.Time) {
+	if b, ok := edp.(baser); ok {
+		b.base().LastValidatedAt = t
+	}
+}
+
+// DeleteProtected reports whether the deletion of the notification endpoint is blocked.
+func DeleteProtected(edp influxdb.NotificationEndpoint) bool {
+	if b, ok := edp.(baser); ok {
+		return b.base().DeleteProtected
+	}
+	return false
+}
+
+// SetDeleteProtected blocks, or allows again, the deletion of the notification endpoint.
+func SetDeleteProtected(edp influxdb.NotificationEndpoint, protected bool) {
+	if b, ok := edp.(baser); ok {
+		b.base().DeleteProtected = protected
+	}
+}
+
+// GroupID returns the ID of the notification endpoint's group, if it belongs to one.
+func GroupID(edp influxdb.NotificationEndpoint) (influxdb.ID, bool) {
+	if b, ok := edp.(baser); ok && b.base().GroupID != nil {
+		return *b.base().GroupID, true
+	}
+	return 0, false
+}
+
+// MaxNotificationsPerHour returns the notification budget of the endpoint over a rolling
+// hour, zero when it has none.
+func MaxNotificationsPerHour(edp influxdb.NotificationEndpoint) int {
+	if b, ok := edp.(baser); ok {
+		return b.base().MaxNotificationsPerHour
+	}
+	return 0
+}
+
+// minSeverities are the check levels an endpoint can be restricted to, by name.
+var minSeverities = map[string]notification.CheckLevel{
+	"ok":   notification.Ok,
+	"info": notification.Info,
+	"warn": notification.Warn,
+	"crit": notification.Critical,
+}
+
+// AcceptsLevel reports whether alerts of the check level are sent to the notification
+// endpoint according to its minimum severity.
+func AcceptsLevel(edp influxdb.NotificationEndpoint, level notification.CheckLevel) bool {
+	b, ok := edp.(baser)
+	if !ok || b.base().MinSeverity == "" {
+		return true
+	}
+	min, ok := minSeverities[b.base().MinSeverity]
+	return !ok || level >= min
+}
+
+// MinSecretRotationInterval is the shortest interval between rotations of the secrets
+// of an endpoint.
+const MinSecretRotationInterval = time.Hour
+
+// RotationPolicy schedules the rotation of the secrets of a notification endpoint.
+type RotationPolicy struct {
+	// Interval is the time between rotations.
+	Interval influxdb.Duration `json:"interval"`
+	// NextRotationAt is when the secrets are next due for rotation, it defaults to
+	// one interval after the policy is set.
+	NextRotationAt *time.Time `json:"nextRotationAt,omitempty"`
+}
+
+// SecretRotation returns the secret rotation policy of the notification endpoint, if
+// it has one.
+func SecretRotation(edp influxdb.NotificationEndpoint) (RotationPolicy, bool) {
+	if b, ok := edp.(baser); ok && b.base().SecretRotation != nil {
+		return *b.base().SecretRotation, true
+	}
+	return RotationPolicy{}, false
+}
+
+// ScheduleSecretRotation sets when the secrets of the notification endpoint are next
+// due for rotation to one interval after now, unless it is already set.
+func ScheduleSecretRotation(edp influxdb.NotificationEndpoint, now time.Time) {
+	b, ok := edp.(baser)
+	if !ok || b.base().SecretRotation == nil || b.base().SecretRotation.NextRotationAt != nil {
+		return
+	}
+	next := now.Add(b.base().SecretRotation.Interval.Duration).UTC()
+	b.base().SecretRotation.NextRotationAt = &next
+}
+
+// Batching coalesces the alerts an endpoint receives within a window into a single
+// message, for digest notifications of noisy systems. It is off when BatchWindow is empty.
+type Batching struct {
+	// BatchWindow is how long alerts are collected before they are sent, e.g. 5m.
+	BatchWindow string `json:"batchWindow,omitempty"`
+	// MaxBatchSize is the most alerts sent in one message, a full batch is sent
+	// before its window closes.
+	MaxBatchSize int `json:"maxBatchSize,omitempty"`
+}
+
+// Window returns the batch window, 0 when batching is off.
+func (b Batching) Window() time.Duration {
+	d, _ := time.ParseDuration(b.BatchWindow)
+	return d
+}
+
+func (b *Batching) normalize() {
+	b.BatchWindow = strings.TrimSpace(b.BatchWindow)
+}
+
+func (b Batching) valid() error {
+	if b.BatchWindow == "" {
+		if b.MaxBatchSize != 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "batch window must be provided to set a max batch size",
+			}
+		}
+		return nil
+	}
+	if d, err := time.ParseDuration(b.BatchWindow); err != nil || d <= 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("batch window %q must be a positive duration", b.BatchWindow),
+		}
+	}
+	if b.MaxBatchSize <= 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "max batch size must be positive",
+		}
+	}
+	return nil
+}
+
+// MergeDefaultTags returns the tags of an alert with the default tags of the
+// notification endpoint added. Tags already set on the alert take precedence.
+func MergeDefaultTags(edp influxdb.NotificationEndpoint, tags map[string]string) map[string]string {
+	b, ok := edp.(baser)
+	if !ok || len(b.base().DefaultTags) == 0 {
+		return tags
+	}
+	merged := make(map[string]string, len(tags)+len(b.base().DefaultTags))
+	for k, v := range b.base().DefaultTags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
+
+// InheritDefaults sets the configuration the notification endpoint leaves unset
+// from the org's defaults.
+func InheritDefaults(edp influxdb.NotificationEndpoint, d influxdb.NotificationEndpointDefaults) {
+	b, ok := edp.(baser)
+	if !ok {
+		return
+	}
+	base := b.base()
+	if base.Timeout == nil && d.Timeout != nil {
+		timeout := *d.Timeout
+		base.Timeout = &timeout
+	}
+	if base.Retries == nil && d.Retries != nil {
+		retries := *d.Retries
+		base.Retries = &retries
+	}
+}
+
+// CopyWithPlaceholderSecrets returns a deep copy of the notification endpoint without
+// an ID whose secret fields are replaced by empty placeholder values. The copy is
+// suitable for creating a new endpoint whose secrets must be filled in later.
+func CopyWithPlaceholderSecrets(edp influxdb.NotificationEndpoint) (influxdb.NotificationEndpoint, error) {
+	b, err := json.Marshal(edp)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	cp, err := UnmarshalJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := cp.(secretPlaceholderer); ok {
+		p.placeholderSecrets()
+	}
+	if b, ok := cp.(baser); ok {
+		b.base().ID = nil
+	}
+	return cp, nil
+}
+
+// AssertNoSecrets checks that a serialized notification endpoint, or a bundle of
+// them under "notificationEndpoints", holds no secret values. Empty placeholders
+// and references to secret keys are allowed. It is a safety net for exports: an
+// error is returned for the first endpoint found carrying a secret value.
+func AssertNoSecrets(b []byte) error {
+	var bundle struct {
+		NotificationEndpoints []json.RawMessage `json:"notificationEndpoints"`
+	}
+	if err := json.Unmarshal(b, &bundle); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode notification endpoints",
+			Err:  err,
+		}
+	}
+	raws := bundle.NotificationEndpoints
+	if raws == nil {
+		raws = []json.RawMessage{b}
+	}
+
+	for _, raw := range raws {
+		edp, err := UnmarshalJSON(raw)
+		if err != nil {
+			return err
+		}
+		fielder, ok := edp.(namedSecretFielder)
+		if !ok {
+			continue
+		}
+		fields := fielder.namedSecretFields()
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v := fields[name].Value; v != nil && *v != "" {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("notification endpoint %q holds a value for secret %s", edp.GetName(), name),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Equal reports whether two notification endpoints have the same configuration.
+// Identity, ownership, timestamps and the muted state are ignored, as are
+// secrets, since their values are not stored on the endpoint.
+func Equal(a, b influxdb.NotificationEndpoint) (bool, error) {
+	ab, err := comparableJSON(a)
+	if err != nil {
+		return false, err
+	}
+	bb, err := comparableJSON(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ab, bb), nil
+}
+
+func comparableJSON(edp influxdb.NotificationEndpoint) ([]byte, error) {
+	cp, err := CopyWithPlaceholderSecrets(edp)
+	if err != nil {
+		return nil, err
+	}
+	cp.Normalize()
+	if b, ok := cp.(baser); ok {
+		b.base().OrgID = nil
+	}
+	cp.SetCreatedAt(time.Time{})
+	cp.SetUpdatedAt(time.Time{})
+	cp.SetMutedUntil(nil)
+
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return b, nil
+}
+
+// secretFieldSet reports whether the secret field references a secret or carries a
+// non empty value.
+func secretFieldSet(fld influxdb.SecretField) bool {
+	return fld.Key != "" || (fld.Value != nil && *fld.Value != "")
+}
+
+// placeholderSecret resets a referenced secret to an empty value so a new key
+// is backfilled for it.
+func placeholderSecret(fld *influxdb.SecretField) {
+	if fld.Key == "" && fld.Value == nil {
+		return
+	}
+	empty := ""
+	fld.Key = ""
+	fld.Value = &empty
+}
+
 // Base is the embed struct of every notification endpoint.
 type Base struct {
 	ID          *influxdb.ID    `json:"id,omitempty"`
@@ -53,6 +582,34 @@ type Base struct {
 	Description string          `json:"description,omitempty"`
 	OrgID       *influxdb.ID    `json:"orgID,omitempty"`
 	Status      influxdb.Status `json:"status"`
+	Priority    int             `json:"priority,omitempty"`
+	MutedUntil  *time.Time      `json:"mutedUntil,omitempty"`
+	// NoTelemetry excludes the endpoint from aggregate usage reporting.
+	NoTelemetry bool `json:"noTelemetry,omitempty"`
+	// Timeout and Retries are inherited from the org's defaults when unset.
+	Timeout *influxdb.Duration `json:"timeout,omitempty"`
+	Retries *int               `json:"retries,omitempty"`
+	// FallbackEndpointID is the endpoint of the same org to notify when this one keeps failing.
+	FallbackEndpointID *influxdb.ID `json:"fallbackEndpointID,omitempty"`
+	// DefaultTags are added to the tags of every alert sent to the endpoint.
+	DefaultTags map[string]string `json:"defaultTags,omitempty"`
+	// GroupID is the notification endpoint group of the org the endpoint belongs to.
+	GroupID *influxdb.ID `json:"groupID,omitempty"`
+	// MaxNotificationsPerHour caps the notifications sent to the endpoint over any
+	// rolling hour, zero means no cap.
+	MaxNotificationsPerHour int `json:"maxNotificationsPerHour,omitempty"`
+	// SecretRotation schedules the rotation of the endpoint's secrets.
+	SecretRotation *RotationPolicy `json:"secretRotation,omitempty"`
+	// MinSeverity is the lowest level of the alerts sent to the endpoint, one of ok, info,
+	// warn or crit. Every alert is sent when it is empty.
+	MinSeverity string `json:"minSeverity,omitempty"`
+	// LastValidatedAt is when a connectivity check of the endpoint last succeeded, it is
+	// read only and only set by recording a validation.
+	LastValidatedAt *time.Time `json:"lastValidatedAt,omitempty"`
+	// DeleteProtected blocks the deletion of the endpoint until it is cleared.
+	DeleteProtected bool `json:"deleteProtected,omitempty"`
+	// AckCallbackURL is the url two-way receivers call to acknowledge an alert.
+	AckCallbackURL string `json:"ackCallbackURL,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -67,6 +624,18 @@ func (b Base) validID() bool {
 	return b.ID != nil && b.ID.Valid()
 }
 
+func (b *Base) normalize() {
+	b.Name = strings.TrimSpace(b.Name)
+	b.Description = strings.TrimSpace(b.Description)
+	b.MinSeverity = strings.ToLower(strings.TrimSpace(b.MinSeverity))
+	b.AckCallbackURL = strings.TrimSpace(b.AckCallbackURL)
+}
+
+// normalizeURL trims whitespace and trailing slashes from a url.
+func normalizeURL(u string) string {
+	return strings.TrimRight(strings.TrimSpace(u), "/")
+}
+
 func (b Base) valid() error {
 	if !b.validID() {
 		return &influxdb.Error{
@@ -86,6 +655,78 @@ func (b Base) valid() error {
 			Msg:  "invalid status",
 		}
 	}
+	if err := influxdb.ValidNotificationEndpointPriority(b.Priority); err != nil {
+		return err
+	}
+	if b.Timeout != nil && b.Timeout.Duration < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Notification Endpoint Timeout can't be negative",
+		}
+	}
+	if b.Retries != nil && *b.Retries < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Notification Endpoint Retries can't be negative",
+		}
+	}
+	if b.MaxNotificationsPerHour < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Notification Endpoint Max Notifications Per Hour can't be negative",
+		}
+	}
+	if b.MinSeverity != "" {
+		if _, ok := minSeverities[b.MinSeverity]; !ok {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("Notification Endpoint Min Severity %q is invalid, it must be one of ok, info, warn or crit", b.MinSeverity),
+			}
+		}
+	}
+	if b.SecretRotation != nil && b.SecretRotation.Interval.Duration < MinSecretRotationInterval {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("Notification Endpoint Secret Rotation Interval must be at least %s", MinSecretRotationInterval),
+		}
+	}
+	if b.FallbackEndpointID != nil {
+		if !b.FallbackEndpointID.Valid() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Fallback Endpoint ID is invalid",
+			}
+		}
+		if *b.FallbackEndpointID == *b.ID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint can't be its own fallback",
+			}
+		}
+	}
+	for k, v := range b.DefaultTags {
+		if k == "" || v == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Default Tags can't have empty keys or values",
+			}
+		}
+		if strings.HasPrefix(k, "_") {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("Notification Endpoint Default Tag key %q is reserved, keys can't start with an underscore", k),
+			}
+		}
+	}
+	if b.AckCallbackURL != "" {
+		u, err := url.Parse(b.AckCallbackURL)
+		if err != nil || u.Host == "" || (!strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https")) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Ack Callback URL must be an absolute http or https url",
+			}
+		}
+	}
 	return nil
 }
 
@@ -122,6 +763,16 @@ func (b *Base) GetStatus() influxdb.Status {
 	return b.Status
 }
 
+// GetPriority implements influxdb.Getter interface.
+func (b *Base) GetPriority() int {
+	return b.Priority
+}
+
+// GetMutedUntil implements influxdb.Getter interface.
+func (b *Base) GetMutedUntil() *time.Time {
+	return b.MutedUntil
+}
+
 // SetID will set the primary key.
 func (b *Base) SetID(id influxdb.ID) {
 	b.ID = &id
@@ -147,6 +798,16 @@ func (b *Base) SetStatus(status influxdb.Status) {
 	b.Status = status
 }
 
+// SetPriority implements influxdb.Updator interface.
+func (b *Base) SetPriority(priority int) {
+	b.Priority = priority
+}
+
+// SetMutedUntil implements influxdb.Updator interface.
+func (b *Base) SetMutedUntil(until *time.Time) {
+	b.MutedUntil = until
+}
+
 func getID(id *influxdb.ID) influxdb.ID {
 	if id == nil {
 		return 0
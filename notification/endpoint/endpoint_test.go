@@ -1,14 +1,19 @@
 package endpoint_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification"
 	"github.com/influxdata/influxdb/notification/endpoint"
 	influxTesting "github.com/influxdata/influxdb/testing"
 )
@@ -70,6 +75,220 @@ func TestValidEndpoint(t *testing.T) {
 				Msg:  "Notification Endpoint Name can't be empty",
 			},
 		},
+		{
+			name: "slack bot mode without channels",
+			src: &endpoint.Slack{
+				Base:  goodBase,
+				URL:   "https://slack.com/api/chat.postMessage",
+				Token: influxdb.SecretField{Key: id1 + "-token"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint channels must be provided in bot mode",
+			},
+		},
+		{
+			name: "slack bot mode with multiple channels",
+			src: &endpoint.Slack{
+				Base:     goodBase,
+				URL:      "https://slack.com/api/chat.postMessage",
+				Token:    influxdb.SecretField{Key: id1 + "-token"},
+				Channels: []string{"#alerts", "#oncall"},
+			},
+		},
+		{
+			name: "slack empty channel",
+			src: &endpoint.Slack{
+				Base:     goodBase,
+				URL:      "https://slack.com/api/chat.postMessage",
+				Token:    influxdb.SecretField{Key: id1 + "-token"},
+				Channels: []string{"#alerts", ""},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint channel can't be empty",
+			},
+		},
+		{
+			name: "priority out of range",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:       influxTesting.MustIDBase16Ptr(id1),
+					Name:     "name1",
+					OrgID:    influxTesting.MustIDBase16Ptr(id3),
+					Status:   influxdb.Active,
+					Priority: influxdb.MaxNotificationEndpointPriority + 1,
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Priority must be between 0 and 100",
+			},
+		},
+		{
+			name: "negative notification budget",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:                      influxTesting.MustIDBase16Ptr(id1),
+					Name:                    "name1",
+					OrgID:                   influxTesting.MustIDBase16Ptr(id3),
+					Status:                  influxdb.Active,
+					MaxNotificationsPerHour: -1,
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Max Notifications Per Hour can't be negative",
+			},
+		},
+		{
+			name: "secret rotation interval too short",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:             influxTesting.MustIDBase16Ptr(id1),
+					Name:           "name1",
+					OrgID:          influxTesting.MustIDBase16Ptr(id3),
+					Status:         influxdb.Active,
+					SecretRotation: &endpoint.RotationPolicy{Interval: influxdb.Duration{Duration: 30 * time.Minute}},
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Secret Rotation Interval must be at least 1h0m0s",
+			},
+		},
+		{
+			name: "secret rotation interval of an hour",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:             influxTesting.MustIDBase16Ptr(id1),
+					Name:           "name1",
+					OrgID:          influxTesting.MustIDBase16Ptr(id3),
+					Status:         influxdb.Active,
+					SecretRotation: &endpoint.RotationPolicy{Interval: influxdb.Duration{Duration: time.Hour}},
+				},
+				URL: "localhost",
+			},
+			err: nil,
+		},
+		{
+			name: "unknown min severity",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16Ptr(id3),
+					Status:      influxdb.Active,
+					MinSeverity: "fatal",
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `Notification Endpoint Min Severity "fatal" is invalid, it must be one of ok, info, warn or crit`,
+			},
+		},
+		{
+			name: "self-referential fallback",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:                 influxTesting.MustIDBase16Ptr(id1),
+					Name:               "name1",
+					OrgID:              influxTesting.MustIDBase16Ptr(id3),
+					Status:             influxdb.Active,
+					FallbackEndpointID: influxTesting.MustIDBase16Ptr(id1),
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint can't be its own fallback",
+			},
+		},
+		{
+			name: "empty default tag value",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16Ptr(id3),
+					Status:      influxdb.Active,
+					DefaultTags: map[string]string{"datacenter": ""},
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Default Tags can't have empty keys or values",
+			},
+		},
+		{
+			name: "reserved default tag key",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16Ptr(id3),
+					Status:      influxdb.Active,
+					DefaultTags: map[string]string{"_check_id": "x"},
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `Notification Endpoint Default Tag key "_check_id" is reserved, keys can't start with an underscore`,
+			},
+		},
+		{
+			name: "ack callback url",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:             influxTesting.MustIDBase16Ptr(id1),
+					Name:           "name1",
+					OrgID:          influxTesting.MustIDBase16Ptr(id3),
+					Status:         influxdb.Active,
+					AckCallbackURL: "https://influx.example.com/api/v2/acks",
+				},
+				URL: "localhost",
+			},
+		},
+		{
+			name: "relative ack callback url",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:             influxTesting.MustIDBase16Ptr(id1),
+					Name:           "name1",
+					OrgID:          influxTesting.MustIDBase16Ptr(id3),
+					Status:         influxdb.Active,
+					AckCallbackURL: "/api/v2/acks",
+				},
+				URL: "localhost",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Ack Callback URL must be an absolute http or https url",
+			},
+		},
+		{
+			name: "ack callback url with unsupported scheme",
+			src: &endpoint.PagerDuty{
+				Base: endpoint.Base{
+					ID:             influxTesting.MustIDBase16Ptr(id1),
+					Name:           "name1",
+					OrgID:          influxTesting.MustIDBase16Ptr(id3),
+					Status:         influxdb.Active,
+					AckCallbackURL: "ftp://influx.example.com/acks",
+				},
+				RoutingKey: influxdb.SecretField{Key: id1 + "-routing-key"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Notification Endpoint Ack Callback URL must be an absolute http or https url",
+			},
+		},
 		{
 			name: "empty slack url",
 			src: &endpoint.Slack{
@@ -136,148 +355,735 @@ func TestValidEndpoint(t *testing.T) {
 				Msg:  "invalid http username/password for basic auth",
 			},
 		},
-	}
-	for _, c := range cases {
-		t.Run(c.name, func(t *testing.T) {
-			got := c.src.Valid()
-			influxTesting.ErrorsEqual(t, got, c.err)
-		})
-	}
-}
-
-var timeGen1 = mock.TimeGenerator{FakeValue: time.Date(2006, time.July, 13, 4, 19, 10, 0, time.UTC)}
-var timeGen2 = mock.TimeGenerator{FakeValue: time.Date(2006, time.July, 14, 5, 23, 53, 10, time.UTC)}
-
-func TestJSON(t *testing.T) {
-	cases := []struct {
-		name string
-		src  influxdb.NotificationEndpoint
-	}{
 		{
-			name: "simple Slack",
-			src: &endpoint.Slack{
-				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
-					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
+			name: "invalid http ca cert",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodGet,
+				AuthMethod: "none",
+				CACert: influxdb.SecretField{
+					Value: strPtr("not a pem"),
 				},
-				URL:   "https://slack.com/api/chat.postMessage",
-				Token: influxdb.SecretField{Key: "token-key-1"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid http ca cert, no PEM encoded certificate found",
 			},
 		},
 		{
-			name: "Slack without token",
-			src: &endpoint.Slack{
-				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
-					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				URL: "https://hooks.slack.com/services/x/y/z",
+			name: "negative http max payload bytes",
+			src: &endpoint.HTTP{
+				Base:            goodBase,
+				URL:             "localhost",
+				Method:          http.MethodPost,
+				AuthMethod:      "none",
+				MaxPayloadBytes: -1,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "http endpoint max payload bytes must not be negative",
 			},
 		},
 		{
-			name: "simple pagerduty",
-			src: &endpoint.PagerDuty{
-				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
-					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				ClientURL:  "https://events.pagerduty.com/v2/enqueue",
-				RoutingKey: influxdb.SecretField{Key: "pagerduty-routing-key"},
+			name: "http field mapping to empty key",
+			src: &endpoint.HTTP{
+				Base:         goodBase,
+				URL:          "localhost",
+				Method:       http.MethodPost,
+				AuthMethod:   "none",
+				FieldMapping: map[string]string{"_message": ""},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `invalid http field mapping "_message", fields must be mapped to non empty utf-8 keys`,
 			},
 		},
 		{
-			name: "simple http",
+			name: "http field mapping to non utf-8 key",
 			src: &endpoint.HTTP{
-				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
-					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				Headers: map[string]string{
-					"x-header-1": "header 1",
-					"x-header-2": "header 2",
-				},
-				AuthMethod: "basic",
-				URL:        "http://example.com",
-				Username:   influxdb.SecretField{Key: "username-key"},
-				Password:   influxdb.SecretField{Key: "password-key"},
+				Base:         goodBase,
+				URL:          "localhost",
+				Method:       http.MethodPost,
+				AuthMethod:   "none",
+				FieldMapping: map[string]string{"_message": "\xff"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `invalid http field mapping "_message", fields must be mapped to non empty utf-8 keys`,
 			},
 		},
-	}
-	for _, c := range cases {
-		b, err := json.Marshal(c.src)
-		if err != nil {
-			t.Fatalf("%s marshal failed, err: %s", c.name, err.Error())
-		}
-		got, err := endpoint.UnmarshalJSON(b)
-		if err != nil {
-			t.Fatalf("%s unmarshal failed, err: %s", c.name, err.Error())
-		}
-		if diff := cmp.Diff(got, c.src); diff != "" {
-			t.Errorf("failed %s, NotificationEndpoint are different -got/+want\ndiff %s", c.name, diff)
-		}
-	}
-}
-
-func TestBackFill(t *testing.T) {
-	cases := []struct {
-		name   string
-		src    influxdb.NotificationEndpoint
-		target influxdb.NotificationEndpoint
-	}{
 		{
-			name: "simple Slack",
+			name: "slack webhook with thread timestamp",
 			src: &endpoint.Slack{
-				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
-					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				URL: "https://slack.com/api/chat.postMessage",
-				Token: influxdb.SecretField{
-					Value: strPtr("token-value"),
-				},
+				Base:     goodBase,
+				URL:      "https://hooks.slack.com/services/x/y/z",
+				ThreadTS: "1503435956.000247",
 			},
-			target: &endpoint.Slack{
-				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
-					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				URL: "https://slack.com/api/chat.postMessage",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint thread replies are only supported in bot mode, a token and a slack web api URL must be provided",
+			},
+		},
+		{
+			name: "slack webhook with broadcast reply",
+			src: &endpoint.Slack{
+				Base:           goodBase,
+				URL:            "https://hooks.slack.com/services/x/y/z",
+				BroadcastReply: true,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint thread replies are only supported in bot mode, a token and a slack web api URL must be provided",
+			},
+		},
+		{
+			name: "slack web api url without token and thread timestamp",
+			src: &endpoint.Slack{
+				Base:     goodBase,
+				URL:      "https://slack.com/api/chat.postMessage",
+				Channels: []string{"#alerts"},
+				ThreadTS: "1503435956.000247",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint thread replies are only supported in bot mode, a token and a slack web api URL must be provided",
+			},
+		},
+		{
+			name: "slack token with webhook url and thread timestamp",
+			src: &endpoint.Slack{
+				Base:     goodBase,
+				URL:      "https://hooks.slack.com/services/x/y/z",
+				Token:    influxdb.SecretField{Key: id1 + "-token"},
+				ThreadTS: "1503435956.000247",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint thread replies are only supported in bot mode, a token and a slack web api URL must be provided",
+			},
+		},
+		{
+			name: "http no auth with token",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "none",
+				Token:      influxdb.SecretField{Key: id1 + "-token"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint token can't be set with auth method "none"`,
+			},
+		},
+		{
+			name: "http no auth with username",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "none",
+				Username:   influxdb.SecretField{Key: id1 + "-username"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint username can't be set with auth method "none"`,
+			},
+		},
+		{
+			name: "http no auth with password value",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "none",
+				Password:   influxdb.SecretField{Value: strPtr("p1")},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint password can't be set with auth method "none"`,
+			},
+		},
+		{
+			name: "http basic auth with token",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "basic",
+				Username:   influxdb.SecretField{Key: id1 + "-username"},
+				Password:   influxdb.SecretField{Key: id1 + "-password"},
+				Token:      influxdb.SecretField{Key: id1 + "-token"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint token can't be set with auth method "basic"`,
+			},
+		},
+		{
+			name: "http bearer auth with username",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "bearer",
+				Token:      influxdb.SecretField{Key: id1 + "-token"},
+				Username:   influxdb.SecretField{Key: id1 + "-username"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint username can't be set with auth method "bearer"`,
+			},
+		},
+		{
+			name: "http bearer auth with password",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "bearer",
+				Token:      influxdb.SecretField{Key: id1 + "-token"},
+				Password:   influxdb.SecretField{Key: id1 + "-password"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint password can't be set with auth method "bearer"`,
+			},
+		},
+		{
+			name: "pagerduty with dedup key template",
+			src: &endpoint.PagerDuty{
+				Base:             goodBase,
+				ClientURL:        "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey:       influxdb.SecretField{Key: id1 + "-routing-key"},
+				DedupKeyTemplate: "{{.checkName}}-{{.host}}",
+			},
+		},
+		{
+			name: "pagerduty with malformed dedup key template",
+			src: &endpoint.PagerDuty{
+				Base:             goodBase,
+				ClientURL:        "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey:       influxdb.SecretField{Key: id1 + "-routing-key"},
+				DedupKeyTemplate: "{{.checkName",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "pagerduty dedup key template is invalid: template: dedupKey:1: unclosed action",
+			},
+		},
+		{
+			name: "pagerduty with too long on-call schedule id",
+			src: &endpoint.PagerDuty{
+				Base:             goodBase,
+				ClientURL:        "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey:       influxdb.SecretField{Key: id1 + "-routing-key"},
+				OnCallScheduleID: strings.Repeat("x", 257),
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "pagerduty on-call schedule id must not be longer than 256 characters",
+			},
+		},
+		{
+			name: "slack with batching",
+			src: &endpoint.Slack{
+				Base:     goodBase,
+				Batching: endpoint.Batching{BatchWindow: "5m", MaxBatchSize: 20},
+				URL:      "https://hooks.slack.com/services/x/y/z",
+			},
+		},
+		{
+			name: "http with unparseable batch window",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				Batching:   endpoint.Batching{BatchWindow: "often", MaxBatchSize: 20},
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "none",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `batch window "often" must be a positive duration`,
+			},
+		},
+		{
+			name: "http with batch window but no max batch size",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				Batching:   endpoint.Batching{BatchWindow: "1m"},
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "none",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "max batch size must be positive",
+			},
+		},
+		{
+			name: "slack with max batch size but no batch window",
+			src: &endpoint.Slack{
+				Base:     goodBase,
+				Batching: endpoint.Batching{MaxBatchSize: 20},
+				URL:      "https://hooks.slack.com/services/x/y/z",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "batch window must be provided to set a max batch size",
+			},
+		},
+		{
+			name: "http with success status codes",
+			src: &endpoint.HTTP{
+				Base:               goodBase,
+				URL:                "localhost",
+				Method:             http.MethodPost,
+				AuthMethod:         "none",
+				SuccessStatusCodes: []int{http.StatusAccepted, http.StatusNoContent},
+			},
+		},
+		{
+			name: "http with invalid success status code",
+			src: &endpoint.HTTP{
+				Base:               goodBase,
+				URL:                "localhost",
+				Method:             http.MethodPost,
+				AuthMethod:         "none",
+				SuccessStatusCodes: []int{http.StatusOK, 2000},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "http endpoint success status code 2000 is not a valid http status code",
+			},
+		},
+		{
+			name: "http with failover urls",
+			src: &endpoint.HTTP{
+				Base:         goodBase,
+				URL:          "https://us-east.example.com/hook",
+				FailoverURLs: []string{"https://us-west.example.com/hook", "https://eu.example.com/hook"},
+				Method:       http.MethodPost,
+				AuthMethod:   "none",
+			},
+		},
+		{
+			name: "http with malformed failover url",
+			src: &endpoint.HTTP{
+				Base:         goodBase,
+				URL:          "https://us-east.example.com/hook",
+				FailoverURLs: []string{"https://us-west.example.com/hook", "http://[::1"},
+				Method:       http.MethodPost,
+				AuthMethod:   "none",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `http endpoint failover URL "http://[::1" is invalid`,
+			},
+		},
+		{
+			name: "http with empty failover url",
+			src: &endpoint.HTTP{
+				Base:         goodBase,
+				URL:          "https://us-east.example.com/hook",
+				FailoverURLs: []string{""},
+				Method:       http.MethodPost,
+				AuthMethod:   "none",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "http endpoint failover URL is empty",
+			},
+		},
+		{
+			name: "http with payload compression",
+			src: &endpoint.HTTP{
+				Base:            goodBase,
+				URL:             "localhost",
+				Method:          http.MethodPost,
+				AuthMethod:      "none",
+				CompressPayload: true,
+			},
+		},
+		{
+			name: "http get with payload compression",
+			src: &endpoint.HTTP{
+				Base:            goodBase,
+				URL:             "localhost",
+				Method:          http.MethodGet,
+				AuthMethod:      "none",
+				CompressPayload: true,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "http endpoint payload compression requires a method that sends a body, POST or PUT",
+			},
+		},
+		{
+			name: "http no auth with empty placeholder password",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "localhost",
+				Method:     http.MethodPost,
+				AuthMethod: "none",
+				Password:   influxdb.SecretField{Value: strPtr("")},
+			},
+		},
+		{
+			name: "simple discord",
+			src: &endpoint.Discord{
+				Base:      goodBase,
+				URL:       "https://discord.com/api/webhooks/123/abc",
+				Username:  "influxdb",
+				AvatarURL: "https://example.com/avatar.png",
+			},
+		},
+		{
+			name: "discord without url",
+			src: &endpoint.Discord{
+				Base: goodBase,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "discord endpoint URL must be provided",
+			},
+		},
+		{
+			name: "discord with http url",
+			src: &endpoint.Discord{
+				Base: goodBase,
+				URL:  "http://discord.com/api/webhooks/123/abc",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "discord endpoint URL must be an absolute https url",
+			},
+		},
+		{
+			name: "discord with relative url",
+			src: &endpoint.Discord{
+				Base: goodBase,
+				URL:  "discord.com/api/webhooks/123/abc",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "discord endpoint URL must be an absolute https url",
+			},
+		},
+		{
+			name: "discord with long username",
+			src: &endpoint.Discord{
+				Base:     goodBase,
+				URL:      "https://discord.com/api/webhooks/123/abc",
+				Username: strings.Repeat("a", 81),
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "discord endpoint username must not be longer than 80 characters",
+			},
+		},
+		{
+			name: "discord with relative avatar url",
+			src: &endpoint.Discord{
+				Base:      goodBase,
+				URL:       "https://discord.com/api/webhooks/123/abc",
+				AvatarURL: "avatar.png",
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "discord endpoint avatar URL must be an absolute url",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.src.Valid()
+			influxTesting.ErrorsEqual(t, got, c.err)
+		})
+	}
+}
+
+var timeGen1 = mock.TimeGenerator{FakeValue: time.Date(2006, time.July, 13, 4, 19, 10, 0, time.UTC)}
+var timeGen2 = mock.TimeGenerator{FakeValue: time.Date(2006, time.July, 14, 5, 23, 53, 10, time.UTC)}
+
+func TestJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		src  influxdb.NotificationEndpoint
+	}{
+		{
+			name: "simple Slack",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL:      "https://slack.com/api/chat.postMessage",
+				Token:    influxdb.SecretField{Key: "token-key-1"},
+				Channels: []string{"#alerts", "#oncall"},
+			},
+		},
+		{
+			name: "Slack with default tags",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16Ptr(id3),
+					Status:      influxdb.Active,
+					DefaultTags: map[string]string{"datacenter": "us-east"},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://hooks.slack.com/services/x/y/z",
+			},
+		},
+		{
+			name: "Slack without token",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://hooks.slack.com/services/x/y/z",
+			},
+		},
+		{
+			name: "simple pagerduty",
+			src: &endpoint.PagerDuty{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey: influxdb.SecretField{Key: "pagerduty-routing-key"},
+			},
+		},
+		{
+			name: "pagerduty without telemetry",
+			src: &endpoint.PagerDuty{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16Ptr(id3),
+					Status:      influxdb.Active,
+					NoTelemetry: true,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey: influxdb.SecretField{Key: "pagerduty-routing-key"},
+			},
+		},
+		{
+			name: "simple http",
+			src: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Headers: map[string]string{
+					"x-header-1": "header 1",
+					"x-header-2": "header 2",
+				},
+				AuthMethod: "basic",
+				URL:        "http://example.com",
+				Username:   influxdb.SecretField{Key: "username-key"},
+				Password:   influxdb.SecretField{Key: "password-key"},
+			},
+		},
+		{
+			name: "http with max payload bytes",
+			src: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				AuthMethod:      "none",
+				Method:          http.MethodPost,
+				URL:             "https://example.com",
+				MaxPayloadBytes: 65536,
+			},
+		},
+		{
+			name: "http with ca cert",
+			src: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				AuthMethod: "none",
+				Method:     "POST",
+				URL:        "https://internal.example.com",
+				CACert:     influxdb.SecretField{Key: id1 + "-ca-cert"},
+			},
+		},
+		{
+			name: "simple discord",
+			src: &endpoint.Discord{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL:       "https://discord.com/api/webhooks/123/abc",
+				Username:  "influxdb",
+				AvatarURL: "https://example.com/avatar.png",
+			},
+		},
+	}
+	for _, c := range cases {
+		b, err := json.Marshal(c.src)
+		if err != nil {
+			t.Fatalf("%s marshal failed, err: %s", c.name, err.Error())
+		}
+		got, err := endpoint.UnmarshalJSON(b)
+		if err != nil {
+			t.Fatalf("%s unmarshal failed, err: %s", c.name, err.Error())
+		}
+		if diff := cmp.Diff(got, c.src); diff != "" {
+			t.Errorf("failed %s, NotificationEndpoint are different -got/+want\ndiff %s", c.name, diff)
+		}
+	}
+}
+
+func TestMarshalJSONOmitsUnsetSecrets(t *testing.T) {
+	cases := []struct {
+		name    string
+		src     influxdb.NotificationEndpoint
+		omitted []string
+	}{
+		{
+			name: "slack webhook without token",
+			src: &endpoint.Slack{
+				Base: goodBase,
+				URL:  "https://hooks.slack.com/services/x/y/z",
+			},
+			omitted: []string{"token"},
+		},
+		{
+			name: "pagerduty without client url",
+			src: &endpoint.PagerDuty{
+				Base:       goodBase,
+				RoutingKey: influxdb.SecretField{Key: "pagerduty-routing-key"},
+			},
+			omitted: []string{"clientURL"},
+		},
+		{
+			name: "http without auth",
+			src: &endpoint.HTTP{
+				Base:       goodBase,
+				URL:        "https://example.com",
+				Method:     "POST",
+				AuthMethod: "none",
+			},
+			omitted: []string{"token", "username", "password", "caCert", "contentTemplate"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := json.Marshal(c.src)
+			if err != nil {
+				t.Fatalf("%s marshal failed, err: %s", c.name, err.Error())
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(b, &fields); err != nil {
+				t.Fatalf("%s unmarshal failed, err: %s", c.name, err.Error())
+			}
+			if fields["type"] != c.src.Type() {
+				t.Errorf("%s expected type %q, got %v", c.name, c.src.Type(), fields["type"])
+			}
+			for _, name := range c.omitted {
+				if v, ok := fields[name]; ok {
+					t.Errorf("%s expected %s to be omitted, got %q", c.name, name, v)
+				}
+			}
+		})
+	}
+}
+
+func TestBackFill(t *testing.T) {
+	cases := []struct {
+		name   string
+		src    influxdb.NotificationEndpoint
+		target influxdb.NotificationEndpoint
+	}{
+		{
+			name: "simple Slack",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://slack.com/api/chat.postMessage",
+				Token: influxdb.SecretField{
+					Value: strPtr("token-value"),
+				},
+			},
+			target: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://slack.com/api/chat.postMessage",
 				Token: influxdb.SecretField{
 					Key:   id1 + "-token",
 					Value: strPtr("token-value"),
@@ -285,97 +1091,894 @@ func TestBackFill(t *testing.T) {
 			},
 		},
 		{
-			name: "simple pagerduty",
+			name: "simple pagerduty",
+			src: &endpoint.PagerDuty{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				ClientURL: "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey: influxdb.SecretField{
+					Value: strPtr("routing-key-value"),
+				},
+			},
+			target: &endpoint.PagerDuty{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				ClientURL: "https://events.pagerduty.com/v2/enqueue",
+				RoutingKey: influxdb.SecretField{
+					Key:   id1 + "-routing-key",
+					Value: strPtr("routing-key-value"),
+				},
+			},
+		},
+		{
+			name: "http with token",
+			src: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				AuthMethod: "basic",
+				URL:        "http://example.com",
+				Username: influxdb.SecretField{
+					Value: strPtr("username1"),
+				},
+				Password: influxdb.SecretField{
+					Value: strPtr("password1"),
+				},
+			},
+			target: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				AuthMethod: "basic",
+				URL:        "http://example.com",
+				Username: influxdb.SecretField{
+					Key:   id1 + "-username",
+					Value: strPtr("username1"),
+				},
+				Password: influxdb.SecretField{
+					Key:   id1 + "-password",
+					Value: strPtr("password1"),
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		c.src.BackfillSecretKeys()
+		if diff := cmp.Diff(c.target, c.src); diff != "" {
+			t.Errorf("failed %s, NotificationEndpoint are different -got/+want\ndiff %s", c.name, diff)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		msg  string
+	}{
+		{
+			name: "valid slack",
+			body: `{"type":"slack","name":"n1","status":"active","url":"https://hooks.slack.com/services/x/y/z"}`,
+		},
+		{
+			name: "valid slack with token value",
+			body: `{"type":"slack","name":"n1","status":"active","url":"https://slack.com/api/chat.postMessage","token":"tok1","channels":["#alerts"]}`,
+		},
+		{
+			name: "slack without url",
+			body: `{"type":"slack","name":"n1","status":"active"}`,
+			msg:  "slack endpoint URL must be provided",
+		},
+		{
+			name: "slack without name",
+			body: `{"type":"slack","status":"active","url":"https://hooks.slack.com/services/x/y/z"}`,
+			msg:  "Notification Endpoint Name can't be empty",
+		},
+		{
+			name: "valid pagerduty",
+			body: `{"type":"pagerduty","name":"n1","status":"active","clientURL":"https://events.pagerduty.com/v2/enqueue","routingKey":"rk1"}`,
+		},
+		{
+			name: "pagerduty without routing key",
+			body: `{"type":"pagerduty","name":"n1","status":"active","clientURL":"https://events.pagerduty.com/v2/enqueue"}`,
+			msg:  "pagerduty routing key is invalid",
+		},
+		{
+			name: "pagerduty with bad status",
+			body: `{"type":"pagerduty","name":"n1","status":"sleeping","routingKey":"rk1"}`,
+			msg:  "invalid status",
+		},
+		{
+			name: "valid http",
+			body: `{"type":"http","name":"n1","status":"active","url":"http://example.com","method":"POST","authMethod":"basic","username":"u1","password":"p1"}`,
+		},
+		{
+			name: "http with bad method",
+			body: `{"type":"http","name":"n1","status":"active","url":"http://example.com","method":"DELETE","authMethod":"none"}`,
+			msg:  "invalid http http method",
+		},
+		{
+			name: "http basic auth without password",
+			body: `{"type":"http","name":"n1","status":"active","url":"http://example.com","method":"POST","authMethod":"basic","username":"u1"}`,
+			msg:  "invalid http username/password for basic auth",
+		},
+		{
+			name: "http with negative max payload bytes",
+			body: `{"type":"http","name":"n1","status":"active","url":"http://example.com","method":"POST","authMethod":"none","maxPayloadBytes":-1}`,
+			msg:  "http endpoint max payload bytes must not be negative",
+		},
+		{
+			name: "http with priority out of range",
+			body: `{"type":"http","name":"n1","status":"active","url":"http://example.com","method":"POST","authMethod":"none","priority":101}`,
+			msg:  "Notification Endpoint Priority must be between 0 and 100",
+		},
+		{
+			name: "http no auth with password",
+			body: `{"type":"http","name":"n1","status":"active","url":"http://example.com","method":"POST","authMethod":"none","password":"p1"}`,
+			msg:  `http endpoint password can't be set with auth method "none"`,
+		},
+		{
+			name: "slack webhook with thread timestamp",
+			body: `{"type":"slack","name":"n1","status":"active","url":"https://hooks.slack.com/services/x/y/z","threadTS":"1503435956.000247"}`,
+			msg:  "slack endpoint thread replies are only supported in bot mode, a token and a slack web api URL must be provided",
+		},
+		{
+			name: "unknown type",
+			body: `{"type":"carrier-pigeon","name":"n1","status":"active"}`,
+			msg:  "invalid notification endpoint type carrier-pigeon",
+		},
+		{
+			name: "malformed json",
+			body: `{"type":`,
+			msg:  "unable to detect the notification endpoint type from json",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := endpoint.Validate([]byte(c.body))
+			if c.msg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error %q, got nil", c.msg)
+			}
+			if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+				t.Errorf("expected error code %q, got %q", influxdb.EInvalid, code)
+			}
+			if msg := influxdb.ErrorMessage(err); msg != c.msg {
+				t.Errorf("expected error message %q, got %q", c.msg, msg)
+			}
+		})
+	}
+}
+
+func TestMarshalHCL(t *testing.T) {
+	edp := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			ID:          influxTesting.MustIDBase16Ptr(id1),
+			Name:        "2nd pager",
+			Description: "pages ${team}",
+			OrgID:       influxTesting.MustIDBase16Ptr(id3),
+			Status:      influxdb.Active,
+		},
+		ClientURL:  "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey: influxdb.SecretField{Key: id1 + "-routing-key"},
+	}
+	b, err := endpoint.MarshalHCL(edp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `variable "_2nd_pager_routing_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "influxdb_notification_endpoint" "_2nd_pager" {
+  name = "2nd pager"
+  description = "pages $${team}"
+  org_id = "020f755c3c082002"
+  status = "active"
+  type = "pagerduty"
+  client_url = "https://events.pagerduty.com/v2/enqueue"
+  routing_key = var._2nd_pager_routing_key
+}
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("unexpected hcl -want/+got\ndiff %s", diff)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name   string
+		src    influxdb.NotificationEndpoint
+		target influxdb.NotificationEndpoint
+	}{
+		{
+			name: "messy http",
+			src: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "  name1 ",
+					Description: "desc1\n",
+					Status:      influxdb.Active,
+				},
+				URL:        " https://example.com/hook/ ",
+				Method:     " post",
+				AuthMethod: "Basic",
+				Headers: map[string]string{
+					" X-Header-1":  " header 1",
+					"Content-Type": "application/json",
+				},
+			},
+			target: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:          influxTesting.MustIDBase16Ptr(id1),
+					Name:        "name1",
+					Description: "desc1",
+					Status:      influxdb.Active,
+				},
+				URL:        "https://example.com/hook",
+				Method:     "POST",
+				AuthMethod: "basic",
+				Headers: map[string]string{
+					"x-header-1":   "header 1",
+					"content-type": "application/json",
+				},
+			},
+		},
+		{
+			name: "messy slack",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1\t",
+					Status: influxdb.Active,
+				},
+				URL:      "https://slack.com/api/chat.postMessage//",
+				Channels: []string{" #alerts", "#oncall "},
+			},
+			target: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					Status: influxdb.Active,
+				},
+				URL:      "https://slack.com/api/chat.postMessage",
+				Channels: []string{"#alerts", "#oncall"},
+			},
+		},
+		{
+			name: "messy pagerduty",
 			src: &endpoint.PagerDuty{
 				Base: endpoint.Base{
 					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Name:   " name1",
 					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				ClientURL: "https://events.pagerduty.com/v2/enqueue",
-				RoutingKey: influxdb.SecretField{
-					Value: strPtr("routing-key-value"),
 				},
+				ClientURL: "https://events.pagerduty.com/v2/enqueue/ ",
 			},
 			target: &endpoint.PagerDuty{
 				Base: endpoint.Base{
 					ID:     influxTesting.MustIDBase16Ptr(id1),
 					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
 					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
 				},
 				ClientURL: "https://events.pagerduty.com/v2/enqueue",
-				RoutingKey: influxdb.SecretField{
-					Key:   id1 + "-routing-key",
-					Value: strPtr("routing-key-value"),
-				},
 			},
 		},
+	}
+	for _, c := range cases {
+		c.src.Normalize()
+		if diff := cmp.Diff(c.target, c.src); diff != "" {
+			t.Errorf("failed %s, NotificationEndpoint are different -want/+got\ndiff %s", c.name, diff)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	stored := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:     influxTesting.MustIDBase16Ptr(id1),
+			Name:   "name1",
+			OrgID:  influxTesting.MustIDBase16Ptr(id3),
+			Status: influxdb.Active,
+			CRUDLog: influxdb.CRUDLog{
+				CreatedAt: timeGen1.Now(),
+				UpdatedAt: timeGen2.Now(),
+			},
+		},
+		URL:   "https://slack.com/api/chat.postMessage",
+		Token: influxdb.SecretField{Key: id1 + "-token"},
+	}
+
+	cases := []struct {
+		name    string
+		desired influxdb.NotificationEndpoint
+		equal   bool
+	}{
 		{
-			name: "http with token",
-			src: &endpoint.HTTP{
+			name: "same config without identity",
+			desired: &endpoint.Slack{
 				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
-					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Name:   "name1 ",
 					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				AuthMethod: "basic",
-				URL:        "http://example.com",
-				Username: influxdb.SecretField{
-					Value: strPtr("username1"),
 				},
-				Password: influxdb.SecretField{
-					Value: strPtr("password1"),
+				URL:   "https://slack.com/api/chat.postMessage/",
+				Token: influxdb.SecretField{Value: strPtr("token")},
+			},
+			equal: true,
+		},
+		{
+			name: "different status",
+			desired: &endpoint.Slack{
+				Base: endpoint.Base{
+					Name:   "name1",
+					Status: influxdb.Inactive,
 				},
+				URL: "https://slack.com/api/chat.postMessage",
 			},
-			target: &endpoint.HTTP{
+		},
+		{
+			name: "different type",
+			desired: &endpoint.PagerDuty{
 				Base: endpoint.Base{
-					ID:     influxTesting.MustIDBase16Ptr(id1),
 					Name:   "name1",
-					OrgID:  influxTesting.MustIDBase16Ptr(id3),
 					Status: influxdb.Active,
-					CRUDLog: influxdb.CRUDLog{
-						CreatedAt: timeGen1.Now(),
-						UpdatedAt: timeGen2.Now(),
-					},
-				},
-				AuthMethod: "basic",
-				URL:        "http://example.com",
-				Username: influxdb.SecretField{
-					Key:   id1 + "-username",
-					Value: strPtr("username1"),
-				},
-				Password: influxdb.SecretField{
-					Key:   id1 + "-password",
-					Value: strPtr("password1"),
 				},
+				ClientURL: "https://slack.com/api/chat.postMessage",
 			},
 		},
 	}
 	for _, c := range cases {
-		c.src.BackfillSecretKeys()
-		if diff := cmp.Diff(c.target, c.src); diff != "" {
-			t.Errorf("failed %s, NotificationEndpoint are different -got/+want\ndiff %s", c.name, diff)
+		t.Run(c.name, func(t *testing.T) {
+			eq, err := endpoint.Equal(stored, c.desired)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if eq != c.equal {
+				t.Errorf("got %v, want %v", eq, c.equal)
+			}
+		})
+	}
+}
+
+func TestPreviewPayload(t *testing.T) {
+	alert := map[string]interface{}{
+		"_message": "cpu is high",
+		"host":     "server01",
+	}
+
+	cases := []struct {
+		name     string
+		edp      influxdb.NotificationEndpoint
+		template string
+		want     endpoint.Preview
+	}{
+		{
+			name: "slack with default message template",
+			edp: &endpoint.Slack{
+				URL:      "https://hooks.slack.com/services/x",
+				Channels: []string{"#ops"},
+			},
+			want: endpoint.Preview{
+				URL:  "https://hooks.slack.com/services/x",
+				Body: `{"channel":"#ops","text":"cpu is high"}`,
+			},
+		},
+		{
+			name: "pagerduty with message template",
+			edp: &endpoint.PagerDuty{
+				ClientURL:  "https://example.com",
+				RoutingKey: influxdb.SecretField{Value: strPtr("s3cr3t")},
+			},
+			template: "${r.host}: ${r._message}${r.missing}",
+			want: endpoint.Preview{
+				URL:  "https://events.pagerduty.com/v2/enqueue",
+				Body: `{"client_url":"https://example.com","event_action":"trigger","payload":{"summary":"server01: cpu is high"}}`,
+			},
+		},
+		{
+			name: "pagerduty with dedup key template",
+			edp: &endpoint.PagerDuty{
+				ClientURL:        "https://example.com",
+				RoutingKey:       influxdb.SecretField{Value: strPtr("s3cr3t")},
+				DedupKeyTemplate: "{{.host}}-cpu",
+			},
+			want: endpoint.Preview{
+				URL:  "https://events.pagerduty.com/v2/enqueue",
+				Body: `{"client_url":"https://example.com","dedup_key":"server01-cpu","event_action":"trigger","payload":{"summary":"cpu is high"}}`,
+			},
+		},
+		{
+			name: "http without content template",
+			edp: &endpoint.HTTP{
+				URL: "https://example.com/hook",
+			},
+			want: endpoint.Preview{
+				URL:  "https://example.com/hook",
+				Body: `{"_message":"cpu is high","host":"server01"}`,
+			},
+		},
+		{
+			name: "http with field mapping",
+			edp: &endpoint.HTTP{
+				URL:          "https://example.com/hook",
+				FieldMapping: map[string]string{"_message": "text"},
+			},
+			want: endpoint.Preview{
+				URL:  "https://example.com/hook",
+				Body: `{"host":"server01","text":"cpu is high"}`,
+			},
+		},
+		{
+			name: "discord with username override",
+			edp: &endpoint.Discord{
+				URL:      "https://discord.com/api/webhooks/123/abc",
+				Username: "influxdb",
+			},
+			template: "${r.host}: ${r._message}",
+			want: endpoint.Preview{
+				URL:  "https://discord.com/api/webhooks/123/abc",
+				Body: `{"content":"server01: cpu is high","username":"influxdb"}`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := endpoint.PreviewPayload(c.edp, alert, c.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("preview is different -want/+got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPreviewPayload_DedupKeyMissingField(t *testing.T) {
+	edp := &endpoint.PagerDuty{
+		RoutingKey:       influxdb.SecretField{Value: strPtr("s3cr3t")},
+		DedupKeyTemplate: "{{.missing}}",
+	}
+	_, err := endpoint.PreviewPayload(edp, map[string]interface{}{"_message": "cpu is high"}, "")
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected error code %q, got %q: %v", influxdb.EInvalid, code, err)
+	}
+}
+
+func TestUnmarshalJSON_Malformed(t *testing.T) {
+	bodies := map[string]string{
+		"slack":     `{"type":"slack","name":1}`,
+		"pagerduty": `{"type":"pagerduty","routingKey":[]}`,
+		"http":      `{"type":"http","headers":"x"}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			edp, err := endpoint.UnmarshalJSON([]byte(body))
+			if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+				t.Fatalf("expected error code %q, got %q: %v", influxdb.EInvalid, code, err)
+			}
+			if edp != nil {
+				t.Fatalf("expected a nil endpoint, got %#v", edp)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSONStrict(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		extra []string
+		msg   string
+	}{
+		{
+			name: "valid",
+			body: `{"type":"slack","name":"slack","status":"active","url":"https://hooks.slack.com/services/x"}`,
+		},
+		{
+			name:  "allowed extra field",
+			body:  `{"type":"slack","name":"slack","labels":["l1"]}`,
+			extra: []string{"labels"},
+		},
+		{
+			name: "unknown field",
+			body: `{"type":"slack","name":"slack","chanel":"#alerts"}`,
+			msg:  `invalid slack notification endpoint: unknown field "chanel"`,
+		},
+		{
+			name: "unknown nested field",
+			body: `{"type":"pagerduty","name":"pd","secretRotation":{"interval":"24h","every":"day"}}`,
+			msg:  `invalid pagerduty notification endpoint: unknown field "every"`,
+		},
+		{
+			name: "type mismatch",
+			body: `{"type":"slack","name":1}`,
+			msg:  `invalid slack notification endpoint: field "name" at offset 24 must be string, got number`,
+		},
+		{
+			name: "syntax error",
+			body: `{"type":"slack",}`,
+			msg:  "invalid notification endpoint: syntax error at offset 17: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			name: "unknown type",
+			body: `{"type":"email"}`,
+			msg:  `invalid notification endpoint type "email"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			edp, err := endpoint.UnmarshalJSONStrict([]byte(c.body), c.extra...)
+			if c.msg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if edp == nil {
+					t.Fatal("expected an endpoint")
+				}
+				return
+			}
+			if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+				t.Fatalf("expected error code %q, got %q: %v", influxdb.EInvalid, code, err)
+			}
+			if msg := influxdb.ErrorMessage(err); msg != c.msg {
+				t.Errorf("expected error message %q, got %q", c.msg, msg)
+			}
+		})
+	}
+}
+
+func TestIsNil(t *testing.T) {
+	var slack *endpoint.Slack
+	for _, edp := range []influxdb.NotificationEndpoint{nil, slack, (*endpoint.PagerDuty)(nil), (*endpoint.HTTP)(nil), (*endpoint.Discord)(nil)} {
+		if !endpoint.IsNil(edp) {
+			t.Errorf("expected %#v to be nil", edp)
+		}
+	}
+	if endpoint.IsNil(&endpoint.Slack{}) {
+		t.Error("expected a non nil slack endpoint")
+	}
+}
+
+func TestHTTP_IsSuccessStatus(t *testing.T) {
+	edp := endpoint.HTTP{}
+	for code, want := range map[int]bool{200: true, 204: true, 299: true, 302: false, 500: false} {
+		if got := edp.IsSuccessStatus(code); got != want {
+			t.Errorf("expected %d to count as success %t by default, got %t", code, want, got)
+		}
+	}
+
+	edp.SuccessStatusCodes = []int{202, 409}
+	for code, want := range map[int]bool{200: false, 202: true, 409: true} {
+		if got := edp.IsSuccessStatus(code); got != want {
+			t.Errorf("expected %d to count as success %t, got %t", code, want, got)
+		}
+	}
+}
+
+func TestAssertNoSecrets(t *testing.T) {
+	slack := &endpoint.Slack{
+		Base:  goodBase,
+		URL:   "https://slack.com/api/chat.postMessage",
+		Token: influxdb.SecretField{Key: id1 + "-token", Value: strPtr("xoxb-s3cr3t")},
+	}
+	redacted, err := endpoint.CopyWithPlaceholderSecrets(slack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(map[string]interface{}{
+		"notificationEndpoints": []influxdb.NotificationEndpoint{redacted, slack},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := endpoint.AssertNoSecrets(b); err != nil {
+		t.Fatalf("expected a redacted bundle to pass, got %v", err)
+	}
+
+	leaky := `{"notificationEndpoints":[
+		{"type":"slack","name":"ok","url":"https://hooks.slack.com/services/x/y/z"},
+		{"type":"http","name":"leaky","url":"http://example.com","authMethod":"basic","username":"secret: user-key","password":"hunter2"}
+	]}`
+	err = endpoint.AssertNoSecrets([]byte(leaky))
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected error code %q, got %q: %v", influxdb.EInvalid, code, err)
+	}
+	if err := endpoint.AssertNoSecrets([]byte(`{"type":"pagerduty","name":"pd","routingKey":"r0ut1ng"}`)); err == nil {
+		t.Fatal("expected an error for a single endpoint holding a secret value")
+	}
+}
+
+func TestSetSecretFields(t *testing.T) {
+	edp := &endpoint.HTTP{
+		Base:       goodBase,
+		URL:        "https://example.com/hook",
+		Method:     "POST",
+		AuthMethod: "basic",
+		Username:   influxdb.SecretField{Key: id1 + "-username"},
+		Password:   influxdb.SecretField{Value: strPtr("s3cr3t"), Store: "kms"},
+	}
+	want := []influxdb.SecretField{
+		{Value: strPtr("s3cr3t"), Store: "kms"},
+		{Key: id1 + "-username"},
+	}
+	if diff := cmp.Diff(want, endpoint.SetSecretFields(edp)); diff != "" {
+		t.Errorf("set secret fields differ (-want +got):\n%s", diff)
+	}
+	if got := endpoint.SetSecretFields(&endpoint.Discord{Base: goodBase}); len(got) != 0 {
+		t.Errorf("expected no secret fields, got %v", got)
+	}
+}
+
+func TestAcceptsLevel(t *testing.T) {
+	all := &endpoint.Slack{Base: goodBase}
+	critOnly := &endpoint.Slack{Base: goodBase}
+	critOnly.MinSeverity = "crit"
+	warnAndUp := &endpoint.Slack{Base: goodBase}
+	warnAndUp.MinSeverity = "warn"
+
+	cases := []struct {
+		edp   influxdb.NotificationEndpoint
+		level notification.CheckLevel
+		want  bool
+	}{
+		{edp: all, level: notification.Ok, want: true},
+		{edp: critOnly, level: notification.Warn, want: false},
+		{edp: critOnly, level: notification.Critical, want: true},
+		{edp: warnAndUp, level: notification.Info, want: false},
+		{edp: warnAndUp, level: notification.Warn, want: true},
+		{edp: warnAndUp, level: notification.Critical, want: true},
+	}
+	for _, c := range cases {
+		if got := endpoint.AcceptsLevel(c.edp, c.level); got != c.want {
+			t.Errorf("min severity %q, level %s: expected %t, got %t", c.edp.(*endpoint.Slack).MinSeverity, c.level, c.want, got)
 		}
 	}
 }
 
+func TestMergeDefaultTags(t *testing.T) {
+	edp := &endpoint.HTTP{
+		Base: endpoint.Base{
+			DefaultTags: map[string]string{"datacenter": "us-east", "team": "ops"},
+		},
+	}
+	got := endpoint.MergeDefaultTags(edp, map[string]string{"host": "server01", "team": "db"})
+	want := map[string]string{"datacenter": "us-east", "host": "server01", "team": "db"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("merged tags are different -want/+got\n%s", diff)
+	}
+
+	tags := map[string]string{"host": "server01"}
+	if diff := cmp.Diff(tags, endpoint.MergeDefaultTags(&endpoint.HTTP{}, tags)); diff != "" {
+		t.Errorf("tags without defaults are different -want/+got\n%s", diff)
+	}
+}
+
 func strPtr(s string) *string {
 	ss := new(string)
 	*ss = s
 	return ss
 }
+
+func TestParseCurl(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	cases := []struct {
+		name string
+		cmd  string
+		want *endpoint.HTTP
+		err  string
+	}{
+		{
+			name: "post with headers and basic auth",
+			cmd: `curl -s -X POST \
+  -H 'Content-Type: application/json' \
+  -H "X-Source: influxdb" \
+  -u alert:s3cr3t \
+  --data-raw '{"text": "cpu is high"}' \
+  https://example.com/alerts`,
+			want: &endpoint.HTTP{
+				URL:             "https://example.com/alerts",
+				Method:          http.MethodPost,
+				AuthMethod:      "basic",
+				Headers:         map[string]string{"Content-Type": "application/json", "X-Source": "influxdb"},
+				Username:        influxdb.SecretField{Value: strPtr("alert")},
+				Password:        influxdb.SecretField{Value: strPtr("s3cr3t")},
+				ContentTemplate: `{"text": "cpu is high"}`,
+			},
+		},
+		{
+			name: "bearer token and attached options",
+			cmd:  `curl -XPUT --url=https://example.com/hook -H "Authorization: Bearer t0k3n" -d "{\"a\":1}"`,
+			want: &endpoint.HTTP{
+				URL:             "https://example.com/hook",
+				Method:          http.MethodPut,
+				AuthMethod:      "bearer",
+				Token:           influxdb.SecretField{Value: strPtr("t0k3n")},
+				ContentTemplate: `{"a":1}`,
+			},
+		},
+		{
+			name: "data defaults to post",
+			cmd:  `curl https://example.com -d ok`,
+			want: &endpoint.HTTP{
+				URL:             "https://example.com",
+				Method:          http.MethodPost,
+				AuthMethod:      "none",
+				ContentTemplate: "ok",
+			},
+		},
+		{
+			name: "no data defaults to get",
+			cmd:  `curl https://example.com/ping`,
+			want: &endpoint.HTTP{
+				URL:        "https://example.com/ping",
+				Method:     http.MethodGet,
+				AuthMethod: "none",
+			},
+		},
+		{
+			name: "not a curl command",
+			cmd:  `wget https://example.com`,
+			err:  "invalid curl command: command must start with curl",
+		},
+		{
+			name: "unsupported option",
+			cmd:  `curl --cert client.pem https://example.com`,
+			err:  `invalid curl command: unsupported option "--cert"`,
+		},
+		{
+			name: "missing url",
+			cmd:  `curl -X POST`,
+			err:  "invalid curl command: url must be provided",
+		},
+		{
+			name: "unterminated quote",
+			cmd:  `curl -H 'Content-Type: application/json https://example.com`,
+			err:  "invalid curl command: unterminated quote",
+		},
+		{
+			name: "basic auth with a bearer token",
+			cmd:  `curl -u a:b -H "Authorization: Bearer t" https://example.com`,
+			err:  "invalid curl command: basic auth can't be combined with a bearer token",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := endpoint.ParseCurl(c.cmd)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("parsed endpoint differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsPrivateAddress(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"93.184.216.34":   false,
+		"172.32.0.1":      false,
+		"2606:4700::1111": false,
+	}
+	for addr, want := range cases {
+		if got := endpoint.IsPrivateAddress(net.ParseIP(addr)); got != want {
+			t.Errorf("IsPrivateAddress(%s) = %t, want %t", addr, got, want)
+		}
+	}
+}
+
+func TestCheckPublicAddresses(t *testing.T) {
+	lookup := func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "public.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+		case "internal.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.1")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	cases := []struct {
+		name    string
+		edp     influxdb.NotificationEndpoint
+		invalid bool
+	}{
+		{
+			name: "public http url",
+			edp:  &endpoint.HTTP{Base: goodBase, URL: "https://public.example.com/hook"},
+		},
+		{
+			name:    "metadata address",
+			edp:     &endpoint.HTTP{Base: goodBase, URL: "http://169.254.169.254/latest/meta-data"},
+			invalid: true,
+		},
+		{
+			name:    "host resolving to a private address",
+			edp:     &endpoint.Slack{Base: goodBase, URL: "https://internal.example.com/hook"},
+			invalid: true,
+		},
+		{
+			name:    "private failover url",
+			edp:     &endpoint.HTTP{Base: goodBase, URL: "https://public.example.com/hook", FailoverURLs: []string{"http://127.0.0.1:8080"}},
+			invalid: true,
+		},
+		{
+			name:    "unresolvable host",
+			edp:     &endpoint.Discord{Base: goodBase, URL: "https://unknown.example.com/api/webhooks/1/a"},
+			invalid: true,
+		},
+		{
+			name: "pagerduty has no configurable url",
+			edp:  &endpoint.PagerDuty{Base: goodBase},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := endpoint.CheckPublicAddresses(context.Background(), c.edp, lookup)
+			if !c.invalid {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
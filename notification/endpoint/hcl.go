@@ -0,0 +1,158 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// HCLResourceType is the terraform resource type used for notification endpoints.
+const HCLResourceType = "influxdb_notification_endpoint"
+
+// MarshalHCL renders the notification endpoint as a terraform resource block.
+// Secret values are never written, every secret the endpoint references is
+// replaced by a variable reference and a matching sensitive variable block.
+func MarshalHCL(edp influxdb.NotificationEndpoint) ([]byte, error) {
+	res := hclResource{name: hclIdentifier(edp.GetName())}
+
+	res.attr("name", edp.GetName())
+	if desc := edp.GetDescription(); desc != "" {
+		res.attr("description", desc)
+	}
+	res.attr("org_id", edp.GetOrgID().String())
+	res.attr("status", string(edp.GetStatus()))
+	if p := edp.GetPriority(); p != 0 {
+		res.rawAttr("priority", strconv.Itoa(p))
+	}
+	res.attr("type", edp.Type())
+
+	switch e := edp.(type) {
+	case *Slack:
+		res.attr("url", e.URL)
+		if len(e.Channels) > 0 {
+			res.listAttr("channels", e.Channels)
+		}
+		res.secret("token", e.Token)
+	case *PagerDuty:
+		res.attr("client_url", e.ClientURL)
+		res.secret("routing_key", e.RoutingKey)
+	case *HTTP:
+		res.attr("url", e.URL)
+		res.attr("method", e.Method)
+		res.attr("auth_method", e.AuthMethod)
+		if e.ContentTemplate != "" {
+			res.attr("content_template", e.ContentTemplate)
+		}
+		if len(e.Headers) > 0 {
+			res.mapAttr("headers", e.Headers)
+		}
+		res.secret("token", e.Token)
+		res.secret("username", e.Username)
+		res.secret("password", e.Password)
+		res.secret("ca_cert", e.CACert)
+	case *Discord:
+		res.attr("url", e.URL)
+		if e.Username != "" {
+			res.attr("username", e.Username)
+		}
+		if e.AvatarURL != "" {
+			res.attr("avatar_url", e.AvatarURL)
+		}
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unable to export notification endpoint type %s as hcl", edp.Type()),
+		}
+	}
+
+	return res.bytes(), nil
+}
+
+type hclResource struct {
+	name    string
+	lines   []string
+	secrets []string
+}
+
+func (r *hclResource) attr(k, v string) {
+	r.rawAttr(k, hclString(v))
+}
+
+func (r *hclResource) rawAttr(k, v string) {
+	r.lines = append(r.lines, fmt.Sprintf("  %s = %s", k, v))
+}
+
+func (r *hclResource) listAttr(k string, l []string) {
+	vals := make([]string, 0, len(l))
+	for _, v := range l {
+		vals = append(vals, hclString(v))
+	}
+	r.rawAttr(k, "["+strings.Join(vals, ", ")+"]")
+}
+
+func (r *hclResource) mapAttr(k string, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for mk := range m {
+		keys = append(keys, mk)
+	}
+	sort.Strings(keys)
+
+	r.lines = append(r.lines, fmt.Sprintf("  %s = {", k))
+	for _, mk := range keys {
+		r.lines = append(r.lines, fmt.Sprintf("    %s = %s", hclString(mk), hclString(m[mk])))
+	}
+	r.lines = append(r.lines, "  }")
+}
+
+func (r *hclResource) secret(k string, fld influxdb.SecretField) {
+	if fld.Key == "" {
+		return
+	}
+	v := r.name + "_" + k
+	r.rawAttr(k, "var."+v)
+	r.secrets = append(r.secrets, v)
+}
+
+func (r *hclResource) bytes() []byte {
+	var buf bytes.Buffer
+	for _, v := range r.secrets {
+		fmt.Fprintf(&buf, "variable %q {\n  type      = string\n  sensitive = true\n}\n\n", v)
+	}
+	fmt.Fprintf(&buf, "resource %q %q {\n", HCLResourceType, r.name)
+	for _, l := range r.lines {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// hclString quotes s as an hcl string literal, escaping template sequences so
+// the value is taken literally.
+func hclString(s string) string {
+	s = strconv.Quote(s)
+	s = strings.Replace(s, "${", "$${", -1)
+	return strings.Replace(s, "%{", "%%{", -1)
+}
+
+// hclIdentifier converts a name into a valid terraform identifier.
+func hclIdentifier(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	id := b.String()
+	if id == "" || (id[0] >= '0' && id[0] <= '9') || id[0] == '-' {
+		id = "_" + id
+	}
+	return id
+}
@@ -1,11 +1,14 @@
 package endpoint
 
 import (
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/influxdata/influxdb"
 )
@@ -16,21 +19,51 @@ const (
 	httpTokenSuffix    = "-token"
 	httpUsernameSuffix = "-username"
 	httpPasswordSuffix = "-password"
+	httpCACertSuffix   = "-ca-cert"
 )
 
 // HTTP is the notification endpoint config of http.
 type HTTP struct {
 	Base
+	Batching
 	// Path is the API path of HTTP
 	URL string `json:"url"`
+	// FailoverURLs are tried in order when sending to URL fails, e.g. the same
+	// receiver in other regions.
+	FailoverURLs []string `json:"failoverURLs,omitempty"`
 	// Token is the bearer token for authorization
-	Headers         map[string]string    `json:"headers,omitempty"`
-	Token           influxdb.SecretField `json:"token,omitempty"`
-	Username        influxdb.SecretField `json:"username,omitempty"`
-	Password        influxdb.SecretField `json:"password,omitempty"`
+	Headers  map[string]string    `json:"headers,omitempty"`
+	Token    influxdb.SecretField `json:"token,omitempty"`
+	Username influxdb.SecretField `json:"username,omitempty"`
+	Password influxdb.SecretField `json:"password,omitempty"`
+	// CACert is the PEM encoded certificate of a private CA trusted by the receiver
+	CACert          influxdb.SecretField `json:"caCert,omitempty"`
 	AuthMethod      string               `json:"authMethod"`
 	Method          string               `json:"method"`
-	ContentTemplate string               `json:"contentTemplate"`
+	ContentTemplate string               `json:"contentTemplate,omitempty"`
+	// MaxPayloadBytes caps the size of the alert body sent to the receiver, 0 means no limit.
+	MaxPayloadBytes int `json:"maxPayloadBytes,omitempty"`
+	// FieldMapping renames alert fields to the keys the receiver expects, e.g. _message to text.
+	FieldMapping map[string]string `json:"fieldMapping,omitempty"`
+	// CompressPayload gzips the alert body sent to the receiver and sets the
+	// Content-Encoding header, for receivers that accept gzip.
+	CompressPayload bool `json:"compressPayload,omitempty"`
+	// SuccessStatusCodes are the response status codes of the receiver that count as
+	// a successful send. Any 2xx code does when it is empty.
+	SuccessStatusCodes []int `json:"successStatusCodes,omitempty"`
+}
+
+// IsSuccessStatus reports whether a response with the status code counts as a successful send.
+func (s HTTP) IsSuccessStatus(code int) bool {
+	if len(s.SuccessStatusCodes) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, c := range s.SuccessStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
 }
 
 // BackfillSecretKeys fill back fill the secret field key during the unmarshalling
@@ -45,6 +78,44 @@ func (s *HTTP) BackfillSecretKeys() {
 	if s.Password.Key == "" && s.Password.Value != nil {
 		s.Password.Key = s.idStr() + httpPasswordSuffix
 	}
+	if s.CACert.Key == "" && s.CACert.Value != nil {
+		s.CACert.Key = s.idStr() + httpCACertSuffix
+	}
+}
+
+func (s *HTTP) placeholderSecrets() {
+	placeholderSecret(&s.Token)
+	placeholderSecret(&s.Username)
+	placeholderSecret(&s.Password)
+	placeholderSecret(&s.CACert)
+}
+
+func (s HTTP) namedSecretFields() map[string]influxdb.SecretField {
+	return map[string]influxdb.SecretField{
+		"token":    s.Token,
+		"username": s.Username,
+		"password": s.Password,
+		"caCert":   s.CACert,
+	}
+}
+
+// Normalize cleans up the user provided configuration.
+func (s *HTTP) Normalize() {
+	s.Base.normalize()
+	s.Batching.normalize()
+	s.URL = normalizeURL(s.URL)
+	for i := range s.FailoverURLs {
+		s.FailoverURLs[i] = normalizeURL(s.FailoverURLs[i])
+	}
+	s.Method = strings.ToUpper(strings.TrimSpace(s.Method))
+	s.AuthMethod = strings.ToLower(strings.TrimSpace(s.AuthMethod))
+	if len(s.Headers) > 0 {
+		headers := make(map[string]string, len(s.Headers))
+		for k, v := range s.Headers {
+			headers[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+		}
+		s.Headers = headers
+	}
 }
 
 // SecretFields return available secret fields.
@@ -59,9 +130,29 @@ func (s HTTP) SecretFields() []influxdb.SecretField {
 	if s.Password.Key != "" {
 		arr = append(arr, s.Password)
 	}
+	if s.CACert.Key != "" {
+		arr = append(arr, s.CACert)
+	}
 	return arr
 }
 
+// Warnings returns the risky, but valid, parts of the configuration.
+func (s HTTP) Warnings() []string {
+	var warnings []string
+	if w := insecureURLWarning(s.URL); w != "" {
+		warnings = append(warnings, w)
+	}
+	for _, u := range s.FailoverURLs {
+		if w := insecureURLWarning(u); w != "" {
+			warnings = append(warnings, fmt.Sprintf("failover url %s: %s", u, w))
+		}
+	}
+	if s.AuthMethod == "none" {
+		warnings = append(warnings, "no authentication: the receiver can't verify notifications come from influxdb")
+	}
+	return warnings
+}
+
 var goodHTTPAuthMethod = map[string]bool{
 	"none":   true,
 	"basic":  true,
@@ -74,11 +165,38 @@ var goodHTTPMethod = map[string]bool{
 	http.MethodPut:  true,
 }
 
+// validAuthCredentials returns an error if the endpoint has credentials its auth
+// method does not send, e.g. a password with the none auth method.
+func (s HTTP) validAuthCredentials() error {
+	creds := []struct {
+		name string
+		fld  influxdb.SecretField
+		used bool
+	}{
+		{name: "token", fld: s.Token, used: s.AuthMethod == "bearer"},
+		{name: "username", fld: s.Username, used: s.AuthMethod == "basic"},
+		{name: "password", fld: s.Password, used: s.AuthMethod == "basic"},
+	}
+	for _, c := range creds {
+		if c.used || !secretFieldSet(c.fld) {
+			continue
+		}
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("http endpoint %s can't be set with auth method %q", c.name, s.AuthMethod),
+		}
+	}
+	return nil
+}
+
 // Valid returns error if some configuration is invalid
 func (s HTTP) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
+	if err := s.Batching.valid(); err != nil {
+		return err
+	}
 	if s.URL == "" {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -91,6 +209,21 @@ func (s HTTP) Valid() error {
 			Msg:  fmt.Sprintf("http endpoint URL is invalid: %s", err.Error()),
 		}
 	}
+	for _, u := range s.FailoverURLs {
+		if u == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "http endpoint failover URL is empty",
+			}
+		}
+		if _, err := url.Parse(u); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("http endpoint failover URL %q is invalid", u),
+				Err:  err,
+			}
+		}
+	}
 	if !goodHTTPMethod[s.Method] {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -115,14 +248,90 @@ func (s HTTP) Valid() error {
 			Msg:  "invalid http token for bearer auth",
 		}
 	}
+	if err := s.validAuthCredentials(); err != nil {
+		return err
+	}
+	if s.MaxPayloadBytes < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "http endpoint max payload bytes must not be negative",
+		}
+	}
+	for _, code := range s.SuccessStatusCodes {
+		if code < 100 || code > 599 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("http endpoint success status code %d is not a valid http status code", code),
+			}
+		}
+	}
+	if s.CompressPayload && s.Method == http.MethodGet {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "http endpoint payload compression requires a method that sends a body, POST or PUT",
+		}
+	}
+	if err := validFieldMapping(s.FieldMapping); err != nil {
+		return err
+	}
+	if s.CACert.Value != nil && *s.CACert.Value != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(*s.CACert.Value)) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid http ca cert, no PEM encoded certificate found",
+			}
+		}
+	}
+
+	return nil
+}
 
+// validFieldMapping returns an error if a field is mapped to an empty or non utf-8 key,
+// or if several fields are mapped to the same key.
+func validFieldMapping(mapping map[string]string) error {
+	keys := make(map[string]string, len(mapping))
+	for field, key := range mapping {
+		if field == "" || key == "" || !utf8.ValidString(key) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid http field mapping %q, fields must be mapped to non empty utf-8 keys", field),
+			}
+		}
+		if other, ok := keys[key]; ok {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid http field mapping, fields %q and %q are both mapped to %q", other, field, key),
+			}
+		}
+		keys[key] = field
+	}
 	return nil
 }
 
+// mapFields returns the alert with its fields renamed by the field mapping.
+// Mapped fields take precedence over unmapped fields of the same name.
+func (s HTTP) mapFields(alert map[string]interface{}) map[string]interface{} {
+	if len(s.FieldMapping) == 0 {
+		return alert
+	}
+	mapped := make(map[string]interface{}, len(alert))
+	for field, v := range alert {
+		if _, ok := s.FieldMapping[field]; !ok {
+			mapped[field] = v
+		}
+	}
+	for field, v := range alert {
+		if key, ok := s.FieldMapping[field]; ok {
+			mapped[key] = v
+		}
+	}
+	return mapped
+}
+
 // MarshalJSON implement json.Marshaler interface.
 func (s HTTP) MarshalJSON() ([]byte, error) {
 	type httpAlias HTTP
-	return json.Marshal(
+	b, err := json.Marshal(
 		struct {
 			httpAlias
 			Type string `json:"type"`
@@ -130,6 +339,10 @@ func (s HTTP) MarshalJSON() ([]byte, error) {
 			httpAlias: httpAlias(s),
 			Type:      s.Type(),
 		})
+	if err != nil {
+		return nil, err
+	}
+	return omitEmptySecretFields(&s, b)
 }
 
 // Type returns the type.
@@ -2,6 +2,9 @@ package endpoint
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
 
 	"github.com/influxdata/influxdb"
 )
@@ -10,14 +13,37 @@ var _ influxdb.NotificationEndpoint = &PagerDuty{}
 
 const routingKeySuffix = "-routing-key"
 
+// maxOnCallScheduleIDLength is the longest on-call schedule id a pagerduty endpoint accepts.
+const maxOnCallScheduleIDLength = 256
+
 // PagerDuty is the notification endpoint config of pagerduty.
 type PagerDuty struct {
 	Base
 	// ClientURL is the url that is presented in the PagerDuty UI when this alert is triggered
-	ClientURL string `json:"clientURL"`
+	ClientURL string `json:"clientURL,omitempty"`
 	// RoutingKey is a version 4 UUID expressed as a 32-digit hexadecimal number.
 	// This is the Integration Key for an integration on any given service.
 	RoutingKey influxdb.SecretField `json:"routingKey"`
+	// DedupKeyTemplate is the go template of the dedup key of an alert, rendered with
+	// the alert fields, e.g. {{.checkName}}-{{.host}}. Alerts with the same dedup key
+	// are grouped into the same incident.
+	DedupKeyTemplate string `json:"dedupKeyTemplate,omitempty"`
+	// OnCallScheduleID identifies the on-call schedule of the service alerts are routed to,
+	// so that UIs can link out to it. It is free-form and never sent to PagerDuty.
+	OnCallScheduleID string `json:"onCallScheduleID,omitempty"`
+}
+
+// dedupKeyTemplate parses the dedup key template. Missing alert fields fail the
+// rendering rather than rendering as "<no value>".
+func (s PagerDuty) dedupKeyTemplate() (*template.Template, error) {
+	tmpl, err := template.New("dedupKey").Option("missingkey=error").Parse(s.DedupKeyTemplate)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("pagerduty dedup key template is invalid: %s", err.Error()),
+		}
+	}
+	return tmpl, nil
 }
 
 // BackfillSecretKeys fill back fill the secret field key during the unmarshalling
@@ -28,6 +54,21 @@ func (s *PagerDuty) BackfillSecretKeys() {
 	}
 }
 
+func (s *PagerDuty) placeholderSecrets() {
+	placeholderSecret(&s.RoutingKey)
+}
+
+func (s PagerDuty) namedSecretFields() map[string]influxdb.SecretField {
+	return map[string]influxdb.SecretField{"routingKey": s.RoutingKey}
+}
+
+// Normalize cleans up the user provided configuration.
+func (s *PagerDuty) Normalize() {
+	s.Base.normalize()
+	s.ClientURL = normalizeURL(s.ClientURL)
+	s.OnCallScheduleID = strings.TrimSpace(s.OnCallScheduleID)
+}
+
 // SecretFields return available secret fields.
 func (s PagerDuty) SecretFields() []influxdb.SecretField {
 	return []influxdb.SecretField{
@@ -46,6 +87,17 @@ func (s PagerDuty) Valid() error {
 			Msg:  "pagerduty routing key is invalid",
 		}
 	}
+	if s.DedupKeyTemplate != "" {
+		if _, err := s.dedupKeyTemplate(); err != nil {
+			return err
+		}
+	}
+	if len(s.OnCallScheduleID) > maxOnCallScheduleIDLength {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("pagerduty on-call schedule id must not be longer than %d characters", maxOnCallScheduleIDLength),
+		}
+	}
 	return nil
 }
 
@@ -53,7 +105,7 @@ type pagerdutyAlias PagerDuty
 
 // MarshalJSON implement json.Marshaler interface.
 func (s PagerDuty) MarshalJSON() ([]byte, error) {
-	return json.Marshal(
+	b, err := json.Marshal(
 		struct {
 			pagerdutyAlias
 			Type string `json:"type"`
@@ -61,6 +113,10 @@ func (s PagerDuty) MarshalJSON() ([]byte, error) {
 			pagerdutyAlias: pagerdutyAlias(s),
 			Type:           s.Type(),
 		})
+	if err != nil {
+		return nil, err
+	}
+	return omitEmptySecretFields(&s, b)
 }
 
 // Type returns the type.
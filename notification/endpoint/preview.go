@@ -0,0 +1,135 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 url alerts are sent to.
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// DeliveryURL returns the url the notification endpoint sends alerts to.
+func DeliveryURL(edp influxdb.NotificationEndpoint) string {
+	switch e := edp.(type) {
+	case *HTTP:
+		return e.URL
+	case *Slack:
+		return e.URL
+	case *PagerDuty:
+		return pagerDutyEventsURL
+	case *Discord:
+		return e.URL
+	}
+	return ""
+}
+
+// Preview is the request a notification endpoint would send for an alert.
+type Preview struct {
+	URL  string `json:"url"`
+	Body string `json:"body"`
+}
+
+// templateRef matches the ${r.field} references of a template.
+var templateRef = regexp.MustCompile(`\$\{\s*r\.([A-Za-z_][A-Za-z0-9_]*)\s*\}`)
+
+// RenderTemplate resolves the ${r.field} references of a template with the fields
+// of a sample alert. References to missing fields render as empty strings.
+func RenderTemplate(tmpl string, alert map[string]interface{}) string {
+	return templateRef.ReplaceAllStringFunc(tmpl, func(ref string) string {
+		v, ok := alert[templateRef.FindStringSubmatch(ref)[1]]
+		if !ok || v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	})
+}
+
+// PreviewPayload renders the request the notification endpoint would send for the
+// sample alert, without sending anything. The HTTP endpoint renders its content
+// template, falling back to the alert as json with its field mapping applied,
+// while the other endpoints render the message template, falling back to the
+// alert's _message field.
+// Secret values are never part of the preview.
+func PreviewPayload(edp influxdb.NotificationEndpoint, alert map[string]interface{}, messageTemplate string) (Preview, error) {
+	if messageTemplate == "" {
+		messageTemplate = "${r._message}"
+	}
+
+	var (
+		p    Preview
+		body interface{}
+	)
+	switch e := edp.(type) {
+	case *HTTP:
+		p.URL = e.URL
+		if e.ContentTemplate != "" {
+			p.Body = RenderTemplate(e.ContentTemplate, alert)
+			return p, nil
+		}
+		body = e.mapFields(alert)
+	case *Slack:
+		p.URL = e.URL
+		msg := map[string]interface{}{
+			"text": RenderTemplate(messageTemplate, alert),
+		}
+		if len(e.Channels) > 0 {
+			msg["channel"] = e.Channels[0]
+		}
+		body = msg
+	case *PagerDuty:
+		p.URL = pagerDutyEventsURL
+		event := map[string]interface{}{
+			"event_action": "trigger",
+			"client_url":   e.ClientURL,
+			"payload": map[string]interface{}{
+				"summary": RenderTemplate(messageTemplate, alert),
+			},
+		}
+		if e.DedupKeyTemplate != "" {
+			tmpl, err := e.dedupKeyTemplate()
+			if err != nil {
+				return Preview{}, err
+			}
+			var key strings.Builder
+			if err := tmpl.Execute(&key, alert); err != nil {
+				return Preview{}, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("unable to render pagerduty dedup key template: %s", err.Error()),
+				}
+			}
+			event["dedup_key"] = key.String()
+		}
+		body = event
+	case *Discord:
+		p.URL = e.URL
+		msg := map[string]interface{}{
+			"content": RenderTemplate(messageTemplate, alert),
+		}
+		if e.Username != "" {
+			msg["username"] = e.Username
+		}
+		if e.AvatarURL != "" {
+			msg["avatar_url"] = e.AvatarURL
+		}
+		body = msg
+	default:
+		return Preview{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unable to preview notification endpoint type %s", edp.Type()),
+		}
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return Preview{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	p.Body = string(b)
+	return p, nil
+}
@@ -4,23 +4,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/influxdata/influxdb"
 )
 
 var _ influxdb.NotificationEndpoint = &Slack{}
 
-const slackTokenSuffix = "-token"
+const (
+	slackTokenSuffix = "-token"
+	// slackAPIPrefix is the prefix of the slack web api urls that bot tokens post to.
+	slackAPIPrefix = "https://slack.com/api/"
+)
 
 // Slack is the notification endpoint config of slack.
 type Slack struct {
 	Base
+	Batching
 	// URL is a valid slack webhook URL
 	// TODO(jm): validate this in unmarshaler
 	// example: https://slack.com/api/chat.postMessage
 	URL string `json:"url"`
 	// Token is the bearer token for authorization
 	Token influxdb.SecretField `json:"token"`
+	// Channels are the channels a bot mode endpoint posts to.
+	Channels []string `json:"channels,omitempty"`
+	// ThreadTS is the timestamp of the parent message a bot mode endpoint replies to.
+	ThreadTS string `json:"threadTS,omitempty"`
+	// BroadcastReply also posts the thread replies of a bot mode endpoint to the channel.
+	BroadcastReply bool `json:"broadcastReply,omitempty"`
+}
+
+// slackTS matches slack message timestamps, e.g. 1503435956.000247
+var slackTS = regexp.MustCompile(`^\d+\.\d+$`)
+
+// BotMode reports whether the endpoint posts through the slack web api
+// with a bot token, rather than through an incoming webhook.
+func (s Slack) BotMode() bool {
+	return s.Token.Key != "" && strings.HasPrefix(s.URL, slackAPIPrefix)
 }
 
 // BackfillSecretKeys fill back fill the secret field key during the unmarshalling
@@ -31,6 +53,25 @@ func (s *Slack) BackfillSecretKeys() {
 	}
 }
 
+func (s *Slack) placeholderSecrets() {
+	placeholderSecret(&s.Token)
+}
+
+func (s Slack) namedSecretFields() map[string]influxdb.SecretField {
+	return map[string]influxdb.SecretField{"token": s.Token}
+}
+
+// Normalize cleans up the user provided configuration.
+func (s *Slack) Normalize() {
+	s.Base.normalize()
+	s.Batching.normalize()
+	s.URL = normalizeURL(s.URL)
+	for i, ch := range s.Channels {
+		s.Channels[i] = strings.TrimSpace(ch)
+	}
+	s.ThreadTS = strings.TrimSpace(s.ThreadTS)
+}
+
 // SecretFields return available secret fields.
 func (s Slack) SecretFields() []influxdb.SecretField {
 	arr := []influxdb.SecretField{}
@@ -45,6 +86,9 @@ func (s Slack) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
+	if err := s.Batching.valid(); err != nil {
+		return err
+	}
 	if s.URL == "" {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -59,14 +103,57 @@ func (s Slack) Valid() error {
 			}
 		}
 	}
+	if s.BotMode() && len(s.Channels) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "slack endpoint channels must be provided in bot mode",
+		}
+	}
+	for _, ch := range s.Channels {
+		if ch == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint channel can't be empty",
+			}
+		}
+	}
+	if !s.BotMode() && (s.ThreadTS != "" || s.BroadcastReply) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "slack endpoint thread replies are only supported in bot mode, a token and a slack web api URL must be provided",
+		}
+	}
+	if s.BotMode() {
+		if s.ThreadTS != "" && !slackTS.MatchString(s.ThreadTS) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("slack endpoint thread timestamp %q is invalid", s.ThreadTS),
+			}
+		}
+		if s.BroadcastReply && s.ThreadTS == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack endpoint thread timestamp must be provided to broadcast replies",
+			}
+		}
+	}
 	return nil
 }
 
+// Warnings returns the risky, but valid, parts of the configuration.
+func (s Slack) Warnings() []string {
+	var warnings []string
+	if w := insecureURLWarning(s.URL); w != "" {
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
 type slackAlias Slack
 
 // MarshalJSON implement json.Marshaler interface.
 func (s Slack) MarshalJSON() ([]byte, error) {
-	return json.Marshal(
+	b, err := json.Marshal(
 		struct {
 			slackAlias
 			Type string `json:"type"`
@@ -74,6 +161,10 @@ func (s Slack) MarshalJSON() ([]byte, error) {
 			slackAlias: slackAlias(s),
 			Type:       s.Type(),
 		})
+	if err != nil {
+		return nil, err
+	}
+	return omitEmptySecretFields(&s, b)
 }
 
 // Type returns the type.
@@ -0,0 +1,144 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// TypeInfo describes a notification endpoint type for UIs.
+type TypeInfo struct {
+	Type           string   `json:"type"`
+	DisplayName    string   `json:"displayName"`
+	Description    string   `json:"description"`
+	RequiredFields []string `json:"requiredFields"`
+	SecretFields   []string `json:"secretFields"`
+}
+
+var typeInfos = map[string]TypeInfo{
+	SlackType: {
+		DisplayName:    "Slack",
+		Description:    "Posts notifications to a Slack incoming webhook, or to channels through the Slack API with a bot token.",
+		RequiredFields: []string{"name", "status", "url"},
+	},
+	PagerDutyType: {
+		DisplayName:    "PagerDuty",
+		Description:    "Triggers and resolves PagerDuty incidents through the Events API v2.",
+		RequiredFields: []string{"name", "status", "routingKey"},
+	},
+	HTTPType: {
+		DisplayName:    "HTTP",
+		Description:    "Sends notifications to any HTTP endpoint, with optional basic or bearer authentication.",
+		RequiredFields: []string{"name", "status", "url", "method", "authMethod"},
+	},
+	DiscordType: {
+		DisplayName:    "Discord",
+		Description:    "Posts notifications to a Discord channel webhook.",
+		RequiredFields: []string{"name", "status", "url"},
+	},
+}
+
+var secretFieldType = reflect.TypeOf(influxdb.SecretField{})
+
+// Types returns the metadata of every registered notification endpoint type, sorted by type.
+func Types() []TypeInfo {
+	types := make([]TypeInfo, 0, len(typeToEndpoint))
+	for typ := range typeToEndpoint {
+		types = append(types, typeInfo(typ))
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// typeInfo returns the metadata of a registered endpoint type.
+func typeInfo(typ string) TypeInfo {
+	info := typeInfos[typ]
+	info.Type = typ
+	info.SecretFields = secretFieldNames(typeToEndpoint[typ]())
+	return info
+}
+
+// secretFieldNames returns the json names of the secret fields of an endpoint.
+func secretFieldNames(edp influxdb.NotificationEndpoint) []string {
+	t := reflect.TypeOf(edp)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	names := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type != secretFieldType {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// InheritSecrets points the secret fields the notification endpoint leaves empty at
+// the secrets of the current endpoint, so replacing an endpoint with an exported copy,
+// whose secrets are empty placeholders, keeps its secrets. Endpoints of different
+// types are left untouched.
+func InheritSecrets(edp, current influxdb.NotificationEndpoint) {
+	dst, src := reflect.ValueOf(edp), reflect.ValueOf(current)
+	if dst.Kind() != reflect.Ptr || dst.Type() != src.Type() {
+		return
+	}
+	dst, src = dst.Elem(), src.Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).Type() != secretFieldType {
+			continue
+		}
+		fld := dst.Field(i).Addr().Interface().(*influxdb.SecretField)
+		if fld.Key != "" || (fld.Value != nil && *fld.Value != "") {
+			continue
+		}
+		cur := src.Field(i).Interface().(influxdb.SecretField)
+		*fld = influxdb.SecretField{Key: cur.Key, Store: cur.Store}
+	}
+}
+
+// omitEmptySecretFields removes the secret fields that are not set from the json
+// encoded notification endpoint. omitempty has no effect on struct fields, so the
+// endpoints' MarshalJSON would otherwise emit them as "".
+func omitEmptySecretFields(edp influxdb.NotificationEndpoint, b []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range secretFieldNames(edp) {
+		if v, ok := fields[name]; ok && string(v) == `""` {
+			delete(fields, name)
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// maskedSecret replaces secret key references hidden from a reader.
+const maskedSecret = "***"
+
+// MaskSecretReferences replaces the secret key references of the json encoded
+// notification endpoint with "***". Secret fields that are not set are left empty.
+func MaskSecretReferences(edp influxdb.NotificationEndpoint, b []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	masked, _ := json.Marshal(maskedSecret)
+	for _, name := range secretFieldNames(edp) {
+		if v, ok := fields[name]; ok && string(v) != `""` {
+			fields[name] = masked
+		}
+	}
+	return json.Marshal(fields)
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
 var (
@@ -23,6 +27,8 @@ type NotificationEndpoint interface {
 	SetName(name string)
 	SetDescription(description string)
 	SetStatus(status Status)
+	SetPriority(priority int)
+	SetMutedUntil(until *time.Time)
 
 	GetID() ID
 	GetCRUDLog() CRUDLog
@@ -30,11 +36,43 @@ type NotificationEndpoint interface {
 	GetName() string
 	GetDescription() string
 	GetStatus() Status
+	GetPriority() int
+	GetMutedUntil() *time.Time
 	// SecretFields return available secret fields.
 	SecretFields() []SecretField
 	// BackfillSecretKeys fill back fill the secret field key during the unmarshalling
 	// if value of that secret field is not nil.
 	BackfillSecretKeys()
+	// Normalize cleans up user provided configuration, so stored endpoints are consistent.
+	Normalize()
+}
+
+type keepNotificationEndpointSecretsKey struct{}
+
+// KeepNotificationEndpointSecrets adds a hint that deleting a notification endpoint
+// should leave its secrets in the secret store.
+func KeepNotificationEndpointSecrets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keepNotificationEndpointSecretsKey{}, true)
+}
+
+// NotificationEndpointSecretsKept retrieves the keep secrets hint.
+func NotificationEndpointSecretsKept(ctx context.Context) bool {
+	keep, _ := ctx.Value(keepNotificationEndpointSecretsKey{}).(bool)
+	return keep
+}
+
+type clientProvidedNotificationEndpointIDKey struct{}
+
+// WithClientProvidedNotificationEndpointID adds a hint that the notification endpoint
+// being created must be stored with the given ID, rather than a generated one.
+func WithClientProvidedNotificationEndpointID(ctx context.Context, id ID) context.Context {
+	return context.WithValue(ctx, clientProvidedNotificationEndpointIDKey{}, id)
+}
+
+// ClientProvidedNotificationEndpointID retrieves the client provided ID hint.
+func ClientProvidedNotificationEndpointID(ctx context.Context) (ID, bool) {
+	id, ok := ctx.Value(clientProvidedNotificationEndpointIDKey{}).(ID)
+	return id, ok
 }
 
 // ops for checks error
@@ -47,11 +85,99 @@ var (
 	OpDeleteNotificationEndpoint   = "DeleteNotificationEndpoint"
 )
 
+// Notification endpoint priority bounds. Endpoints with a higher priority
+// are listed first by default.
+const (
+	MinNotificationEndpointPriority = 0
+	MaxNotificationEndpointPriority = 100
+)
+
+// ValidNotificationEndpointPriority returns an error if the priority is out of range.
+func ValidNotificationEndpointPriority(priority int) error {
+	if priority < MinNotificationEndpointPriority || priority > MaxNotificationEndpointPriority {
+		return &Error{
+			Code: EInvalid,
+			Msg: fmt.Sprintf("Notification Endpoint Priority must be between %d and %d",
+				MinNotificationEndpointPriority, MaxNotificationEndpointPriority),
+		}
+	}
+	return nil
+}
+
+// SortNotificationEndpoints sorts a slice of notification endpoints by a field.
+// When no sort field is provided, endpoints are ordered by descending priority then name.
+// Ties are broken by ascending creation time then ID, so the order is the same across
+// requests and offset pagination never skips or repeats an endpoint.
+// Descending reverses whichever order applies.
+func SortNotificationEndpoints(opts FindOptions, edps []NotificationEndpoint) {
+	var less func(i, j int) bool
+	switch strings.ToLower(opts.SortBy) {
+	case "name":
+		less = func(i, j int) bool {
+			if ni, nj := edps[i].GetName(), edps[j].GetName(); ni != nj {
+				return ni < nj
+			}
+			return notificationEndpointTiebreak(edps[i], edps[j])
+		}
+	case "createdat":
+		less = func(i, j int) bool {
+			return notificationEndpointTiebreak(edps[i], edps[j])
+		}
+	default:
+		less = func(i, j int) bool {
+			if pi, pj := edps[i].GetPriority(), edps[j].GetPriority(); pi != pj {
+				return pi > pj
+			}
+			if ni, nj := edps[i].GetName(), edps[j].GetName(); ni != nj {
+				return ni < nj
+			}
+			return notificationEndpointTiebreak(edps[i], edps[j])
+		}
+	}
+
+	sorter := less
+	if opts.Descending {
+		sorter = func(i, j int) bool {
+			return less(j, i)
+		}
+	}
+	sort.SliceStable(edps, sorter)
+}
+
+// PageNotificationEndpoints returns the page of sorted notification endpoints the
+// offset and limit of the options select.
+func PageNotificationEndpoints(opts FindOptions, edps []NotificationEndpoint) []NotificationEndpoint {
+	if opts.Offset > 0 {
+		if opts.Offset >= len(edps) {
+			return []NotificationEndpoint{}
+		}
+		edps = edps[opts.Offset:]
+	}
+	if opts.Limit > 0 && opts.Limit < len(edps) {
+		edps = edps[:opts.Limit]
+	}
+	return edps
+}
+
+// notificationEndpointTiebreak orders endpoints by ascending creation time then ID.
+func notificationEndpointTiebreak(a, b NotificationEndpoint) bool {
+	if ca, cb := a.GetCRUDLog().CreatedAt, b.GetCRUDLog().CreatedAt; !ca.Equal(cb) {
+		return ca.Before(cb)
+	}
+	return a.GetID() < b.GetID()
+}
+
 // NotificationEndpointFilter represents a set of filter that restrict the returned notification endpoints.
 type NotificationEndpointFilter struct {
 	ID    *ID
 	OrgID *ID
 	Org   *string
+	// GroupID restricts the endpoints to the members of the notification endpoint group.
+	GroupID *ID
+	// ExcludeLabel leaves out the endpoints with a label of that name.
+	ExcludeLabel *string
+	// Name restricts the endpoints to the one with that name.
+	Name *string
 	UserResourceMappingFilter
 }
 
@@ -67,14 +193,74 @@ func (f NotificationEndpointFilter) QueryParams() map[string][]string {
 		qp["org"] = []string{*f.Org}
 	}
 
+	if f.GroupID != nil {
+		qp["group"] = []string{f.GroupID.String()}
+	}
+
+	if f.ExcludeLabel != nil {
+		qp["excludeLabel"] = []string{*f.ExcludeLabel}
+	}
+
+	if f.Name != nil {
+		qp["name"] = []string{*f.Name}
+	}
+
 	return qp
 }
 
+// NotificationEndpointGroup organizes related notification endpoints of an org, e.g.
+// all the endpoints of database alerts.
+type NotificationEndpointGroup struct {
+	ID    ID     `json:"id"`
+	OrgID ID     `json:"orgID"`
+	Name  string `json:"name"`
+}
+
+// Valid returns an error if the notification endpoint group is invalid.
+func (g *NotificationEndpointGroup) Valid() error {
+	if !g.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint Group OrgID is invalid",
+		}
+	}
+	if g.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint Group Name can't be empty",
+		}
+	}
+	return nil
+}
+
+// NotificationEndpointBudget is the usage of the notification budget of an endpoint
+// over the rolling hour ending at a point in time.
+type NotificationEndpointBudget struct {
+	// Max is the most notifications the endpoint may be sent in an hour, zero
+	// means no cap.
+	Max int `json:"max"`
+	// Sent is the number of notifications sent to the endpoint in the hour.
+	Sent int `json:"sent"`
+}
+
+// Exceeded reports whether no more notifications may be sent to the endpoint.
+func (b NotificationEndpointBudget) Exceeded() bool {
+	return b.Max > 0 && b.Sent >= b.Max
+}
+
 // NotificationEndpointUpdate is the set of upgrade fields for patch request.
 type NotificationEndpointUpdate struct {
 	Name        *string `json:"name,omitempty"`
 	Description *string `json:"description,omitempty"`
 	Status      *Status `json:"status,omitempty"`
+	Priority    *int    `json:"priority,omitempty"`
+	// DeleteProtected blocks, or allows again, the deletion of the endpoint.
+	DeleteProtected *bool `json:"deleteProtected,omitempty"`
+
+	// MutedUntil and Unmute are only set through the mute and unmute
+	// routes, the muted state can not be patched directly.
+	MutedUntil *time.Time `json:"-"`
+	Unmute     bool       `json:"-"`
 }
 
 // Valid will verify if the NotificationEndpointUpdate is valid.
@@ -99,9 +285,138 @@ func (n *NotificationEndpointUpdate) Valid() error {
 		}
 	}
 
+	if n.Priority != nil {
+		if err := ValidNotificationEndpointPriority(*n.Priority); err != nil {
+			return err
+		}
+	}
+
+	if n.MutedUntil != nil && n.Unmute {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint can't be muted and unmuted at the same time",
+		}
+	}
+
 	return nil
 }
 
+// NotificationEndpointDefaults are the org-wide defaults of the configuration the org's
+// notification endpoints leave unset.
+type NotificationEndpointDefaults struct {
+	OrgID   ID        `json:"orgID"`
+	Timeout *Duration `json:"timeout,omitempty"`
+	Retries *int      `json:"retries,omitempty"`
+
+	// MaxEndpoints caps the number of notification endpoints of the org, unset means
+	// DefaultMaxNotificationEndpoints.
+	MaxEndpoints *int `json:"maxEndpoints,omitempty"`
+}
+
+// DefaultMaxNotificationEndpoints is the number of notification endpoints an org can
+// create when its defaults set no limit.
+const DefaultMaxNotificationEndpoints = 1000
+
+// MaxNotificationEndpoints returns the maximum number of notification endpoints of the
+// org. It is safe to call on nil defaults.
+func (d *NotificationEndpointDefaults) MaxNotificationEndpoints() int {
+	if d == nil || d.MaxEndpoints == nil {
+		return DefaultMaxNotificationEndpoints
+	}
+	return *d.MaxEndpoints
+}
+
+// Valid returns an error if the notification endpoint defaults are invalid.
+func (d *NotificationEndpointDefaults) Valid() error {
+	if !d.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint Defaults OrgID is invalid",
+		}
+	}
+	if d.Timeout != nil && d.Timeout.Duration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint Defaults Timeout can't be negative",
+		}
+	}
+	if d.Retries != nil && *d.Retries < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint Defaults Retries can't be negative",
+		}
+	}
+	if d.MaxEndpoints != nil && *d.MaxEndpoints < 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "Notification Endpoint Defaults MaxEndpoints must be at least 1",
+		}
+	}
+	return nil
+}
+
+// NotificationEndpointGenerationService reports the generation of the notification
+// endpoints of an org, which changes whenever any of them is created, updated or
+// deleted, so clients can tell a cached listing is stale without diffing it.
+type NotificationEndpointGenerationService interface {
+	// FindNotificationEndpointGeneration returns the generation of the notification endpoints of the org.
+	FindNotificationEndpointGeneration(ctx context.Context, orgID ID) (uint64, error)
+}
+
+// NotificationEndpointVersion is a prior configuration of a notification endpoint, kept
+// when an update replaced it. Secrets are kept as key references, never their values.
+type NotificationEndpointVersion struct {
+	Version              int                  `json:"version"`
+	ReplacedAt           time.Time            `json:"replacedAt"`
+	NotificationEndpoint NotificationEndpoint `json:"notificationEndpoint"`
+}
+
+// NotificationEndpointHistoryService keeps a bounded history of the prior configurations
+// of notification endpoints, for audit and rollback.
+type NotificationEndpointHistoryService interface {
+	// FindNotificationEndpointHistory returns the kept prior configurations of the
+	// notification endpoint, oldest first.
+	FindNotificationEndpointHistory(ctx context.Context, id ID) ([]NotificationEndpointVersion, error)
+
+	// RestoreNotificationEndpointVersion updates the notification endpoint back to a
+	// prior configuration, the configuration it replaces is kept as a new version.
+	RestoreNotificationEndpointVersion(ctx context.Context, id ID, version int, userID ID) (NotificationEndpoint, error)
+}
+
+// NotificationEndpointHealth is the outcome of a connectivity check of a notification endpoint.
+type NotificationEndpointHealth struct {
+	ID      ID     `json:"id"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Error describes why the check failed, it is empty when the endpoint is healthy.
+	Error string `json:"error,omitempty"`
+}
+
+// NotificationEndpointHealthService checks that notification endpoints can be reached.
+type NotificationEndpointHealthService interface {
+	// CheckAll runs a connectivity check of every active notification endpoint of the org.
+	CheckAll(ctx context.Context, orgID ID) ([]NotificationEndpointHealth, error)
+}
+
+// NotificationEndpointSecretService stores the secret values of notification endpoints.
+type NotificationEndpointSecretService interface {
+	// PatchNotificationEndpointSecrets stores the values of the secret fields of the org,
+	// each in the secret store the field asks for.
+	PatchNotificationEndpointSecrets(ctx context.Context, orgID ID, flds []SecretField) error
+	// ValidSecretStores returns an error if a secret field asks for an unknown secret store.
+	ValidSecretStores(flds []SecretField) error
+}
+
+// NotificationEndpointDefaultsService represents a service for managing the org-wide
+// defaults inherited by new notification endpoints.
+type NotificationEndpointDefaultsService interface {
+	// FindNotificationEndpointDefaults returns the notification endpoint defaults of the org.
+	FindNotificationEndpointDefaults(ctx context.Context, orgID ID) (*NotificationEndpointDefaults, error)
+
+	// PutNotificationEndpointDefaults replaces the notification endpoint defaults of the org.
+	PutNotificationEndpointDefaults(ctx context.Context, d *NotificationEndpointDefaults) error
+}
+
 // NotificationEndpointService represents a service for managing notification endpoints.
 type NotificationEndpointService interface {
 	// UserResourceMappingService must be part of all NotificationEndpointStore service,
@@ -188,6 +188,7 @@ func (c *Client) Req(method string, bFn BodyFn, urlPath string, rest ...string)
 // http.Client from the parent httpc.Client. Same connection pool, different specifics.
 func (c *Client) Clone(opts ...ClientOptFn) (*Client, error) {
 	existingOpts := []ClientOptFn{
+		WithAddr(c.addr.String()),
 		WithAuth(c.authFn),
 		withDoer(c.doer),
 		WithRespFn(c.respFn),
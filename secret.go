@@ -34,6 +34,8 @@ type SecretService interface {
 type SecretField struct {
 	Key   string  `json:"key"`
 	Value *string `json:"value,omitempty"`
+	// Store names the secret store holding the secret, the default store when empty.
+	Store string `json:"store,omitempty"`
 }
 
 // String returns the key of the secret.
@@ -44,13 +46,33 @@ func (s SecretField) String() string {
 	return "secret: " + s.Key
 }
 
-// MarshalJSON implement the json marshaler interface.
+// MarshalJSON implement the json marshaler interface. A secret held by a named
+// store is encoded as an object, so the store survives the round trip.
 func (s SecretField) MarshalJSON() ([]byte, error) {
-	return json.Marshal(s.String())
+	if s.Store == "" {
+		return json.Marshal(s.String())
+	}
+	return json.Marshal(struct {
+		Key   string `json:"key,omitempty"`
+		Store string `json:"store"`
+	}{
+		Key:   s.Key,
+		Store: s.Store,
+	})
 }
 
 // UnmarshalJSON implement the json unmarshaler interface.
 func (s *SecretField) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '{' {
+		type secretFieldAlias SecretField
+		var a secretFieldAlias
+		if err := json.Unmarshal(b, &a); err != nil {
+			return err
+		}
+		*s = SecretField(a)
+		return nil
+	}
+
 	var ss string
 	if err := json.Unmarshal(b, &ss); err != nil {
 		return err
@@ -39,6 +39,27 @@ func TestSecretFieldJSON(t *testing.T) {
 				Value: strPtr("some value"),
 			},
 		},
+		{
+			name: "with store",
+			fld: &SecretField{
+				Key:   "some key",
+				Value: strPtr("some value"),
+				Store: "kms",
+			},
+			json: `{"key":"some key","store":"kms"}`,
+			target: SecretField{
+				Key:   "some key",
+				Store: "kms",
+			},
+		},
+		{
+			name: "unmarshal a post with store",
+			json: `{"value":"some value","store":"kms"}`,
+			target: SecretField{
+				Value: strPtr("some value"),
+				Store: "kms",
+			},
+		},
 	}
 	for _, c := range cases {
 		if c.fld != nil {
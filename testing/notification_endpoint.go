@@ -60,6 +60,10 @@ func NotificationEndpointService(
 			name: "FindNotificationEndpoints",
 			fn:   FindNotificationEndpoints,
 		},
+		{
+			name: "FindNotificationEndpointsSortOrder",
+			fn:   FindNotificationEndpointsSortOrder,
+		},
 		{
 			name: "UpdateNotificationEndpoint",
 			fn:   UpdateNotificationEndpoint,
@@ -443,7 +447,10 @@ func FindNotificationEndpoints(
 
 	type wants struct {
 		notificationEndpoints []influxdb.NotificationEndpoint
-		err                   error
+		// total is the count of matches before paging, it defaults to the number of
+		// notification endpoints wanted.
+		total int
+		err   error
 	}
 	tests := []struct {
 		name   string
@@ -951,6 +958,7 @@ func FindNotificationEndpoints(
 						RoutingKey: influxdb.SecretField{Key: fourID + "-routing-key"},
 					},
 				},
+				total: 3,
 			},
 		},
 		{
@@ -1048,6 +1056,7 @@ func FindNotificationEndpoints(
 						AuthMethod: "none",
 					},
 				},
+				total: 3,
 			},
 		},
 		{
@@ -1301,8 +1310,12 @@ func FindNotificationEndpoints(
 
 			edps, n, err := s.FindNotificationEndpoints(ctx, tt.args.filter, tt.args.opts)
 			ErrorsEqual(t, err, tt.wants.err)
-			if n != len(tt.wants.notificationEndpoints) {
-				t.Fatalf("notification endpoints length is different got %d, want %d", n, len(tt.wants.notificationEndpoints))
+			total := tt.wants.total
+			if total == 0 {
+				total = len(tt.wants.notificationEndpoints)
+			}
+			if n != total {
+				t.Fatalf("notification endpoints total is different got %d, want %d", n, total)
 			}
 
 			if diff := cmp.Diff(edps, tt.wants.notificationEndpoints, notificationEndpointCmpOptions...); diff != "" {
@@ -1312,6 +1325,136 @@ func FindNotificationEndpoints(
 	}
 }
 
+// FindNotificationEndpointsSortOrder testing.
+func FindNotificationEndpointsSortOrder(
+	init func(NotificationEndpointFields, *testing.T) (influxdb.NotificationEndpointService, influxdb.SecretService, func()),
+	t *testing.T,
+) {
+	newSlack := func(id, name string, priority int, createdAt time.Time) influxdb.NotificationEndpoint {
+		return &endpoint.Slack{
+			Base: endpoint.Base{
+				ID:       MustIDBase16Ptr(id),
+				OrgID:    MustIDBase16Ptr(fourID),
+				Status:   influxdb.Active,
+				Name:     name,
+				Priority: priority,
+				CRUDLog: influxdb.CRUDLog{
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				},
+			},
+			URL:   "example-slack.com",
+			Token: influxdb.SecretField{Key: id + "-token"},
+		}
+	}
+
+	fields := NotificationEndpointFields{
+		Orgs: []*influxdb.Organization{
+			{
+				ID:   MustIDBase16(fourID),
+				Name: "org4",
+			},
+		},
+		UserResourceMappings: []*influxdb.UserResourceMapping{
+			{
+				ResourceID:   MustIDBase16(oneID),
+				UserID:       MustIDBase16(sixID),
+				UserType:     influxdb.Member,
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+			{
+				ResourceID:   MustIDBase16(twoID),
+				UserID:       MustIDBase16(sixID),
+				UserType:     influxdb.Member,
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+			{
+				ResourceID:   MustIDBase16(threeID),
+				UserID:       MustIDBase16(sixID),
+				UserType:     influxdb.Member,
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+			{
+				ResourceID:   MustIDBase16(fourID),
+				UserID:       MustIDBase16(sixID),
+				UserType:     influxdb.Member,
+				ResourceType: influxdb.NotificationEndpointResourceType,
+			},
+		},
+		NotificationEndpoints: []influxdb.NotificationEndpoint{
+			newSlack(oneID, "edp-a", 0, timeGen1.Now().Add(3*time.Hour)),
+			newSlack(twoID, "edp-b", 50, timeGen1.Now().Add(time.Hour)),
+			newSlack(threeID, "edp-c", 0, timeGen1.Now().Add(2*time.Hour)),
+			newSlack(fourID, "edp-d", 100, timeGen1.Now()),
+		},
+	}
+
+	filter := influxdb.NotificationEndpointFilter{
+		OrgID: idPtr(MustIDBase16(fourID)),
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			UserID:       MustIDBase16(sixID),
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		opts      influxdb.FindOptions
+		wantNames []string
+	}{
+		{
+			name:      "default order is priority then name",
+			wantNames: []string{"edp-d", "edp-b", "edp-a", "edp-c"},
+		},
+		{
+			name:      "default order applies before limit",
+			opts:      influxdb.FindOptions{Limit: 2},
+			wantNames: []string{"edp-d", "edp-b"},
+		},
+		{
+			name:      "default order applies before offset",
+			opts:      influxdb.FindOptions{Offset: 1, Limit: 2},
+			wantNames: []string{"edp-b", "edp-a"},
+		},
+		{
+			name:      "sort by name overrides priority",
+			opts:      influxdb.FindOptions{SortBy: "name"},
+			wantNames: []string{"edp-a", "edp-b", "edp-c", "edp-d"},
+		},
+		{
+			name:      "default order descending",
+			opts:      influxdb.FindOptions{Descending: true},
+			wantNames: []string{"edp-c", "edp-a", "edp-b", "edp-d"},
+		},
+		{
+			name:      "sort by createdAt",
+			opts:      influxdb.FindOptions{SortBy: "createdAt"},
+			wantNames: []string{"edp-d", "edp-b", "edp-c", "edp-a"},
+		},
+		{
+			name:      "sort by createdAt descending applies before limit",
+			opts:      influxdb.FindOptions{SortBy: "createdAt", Descending: true, Limit: 2},
+			wantNames: []string{"edp-a", "edp-c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(fields, t)
+			defer done()
+
+			edps, _, err := s.FindNotificationEndpoints(context.Background(), filter, tt.opts)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(edps))
+			for _, edp := range edps {
+				names = append(names, edp.GetName())
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
 // UpdateNotificationEndpoint testing.
 func UpdateNotificationEndpoint(
 	init func(NotificationEndpointFields, *testing.T) (influxdb.NotificationEndpointService, influxdb.SecretService, func()),
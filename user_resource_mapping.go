@@ -100,6 +100,13 @@ type UserResourceMappingService interface {
 	DeleteUserResourceMapping(ctx context.Context, resourceID, userID ID) error
 }
 
+// ResourceOwnershipService transfers the ownership of resources between users.
+type ResourceOwnershipService interface {
+	// TransferResourceOwnership atomically replaces the owner mapping of fromUserID on
+	// the resource with an owner mapping for toUserID.
+	TransferResourceOwnership(ctx context.Context, resourceType ResourceType, resourceID, fromUserID, toUserID ID) error
+}
+
 // UserResourceMapping represents a mapping of a resource to its user.
 type UserResourceMapping struct {
 	UserID       ID           `json:"userID"`